@@ -1,32 +1,83 @@
 package app
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
+	"log/slog"
+	"net/http"
 	"order_api/app/auth"
 	"order_api/cache"
 	"order_api/config"
 	"order_api/database"
+	"order_api/docs"
+	"order_api/grpcserver"
 	"order_api/handler"
+	"order_api/health"
+	"order_api/metrics"
+	"order_api/outbox"
 	"order_api/repository"
 	"order_api/router"
 	"order_api/service"
+	"order_api/tlsconfig"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/credentials"
 )
 
 type App struct {
+	ctx         context.Context
+	cancel      context.CancelFunc
 	config      *config.Config
-	db          *database.Database
-	cache       *cache.Cache
+	reloader    *config.Reloader
+	logLevel    *slog.LevelVar
+	cluster     *database.Cluster
+	cache       cache.Store
 	router      *gin.Engine
+	server      *http.Server
+	health      *health.Health
+	certs       *tlsconfig.Manager
+	grpc        *grpcserver.Server
+	workers     sync.WaitGroup
 	authService *auth.AuthService
 }
 
-func NewApp() *App {
-	return &App{
-		config: config.NewConfig(),
+func NewApp(cfg *config.Config) *App {
+	if files := cfg.Files(); len(files) > 0 {
+		log.Printf("Loaded config from %s", strings.Join(files, ", "))
 	}
+	for _, setting := range cfg.Settings() {
+		if setting.Source == config.SourceEnv || setting.Source == config.SourceFlag {
+			log.Printf("Config %s overridden by %s", setting.Key, setting.Source)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	a := &App{
+		ctx:      ctx,
+		cancel:   cancel,
+		config:   cfg,
+		reloader: config.NewReloader(cfg),
+	}
+	a.initLogger()
+	return a
+}
+
+// initLogger 设置结构化日志的级别，log 包的输出不受影响
+func (a *App) initLogger() {
+	a.logLevel = new(slog.LevelVar)
+	a.logLevel.Set(a.config.Log.SlogLevel())
+	slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: a.logLevel})))
+	// SetDefault 会把 log 包的输出转到 slog 的 Info 级别，恢复原样以免调高级别后丢失启动和致命错误日志
+	log.SetOutput(os.Stderr)
+	log.SetFlags(log.LstdFlags)
 }
 
 func (a *App) Initialize() error {
@@ -40,24 +91,162 @@ func (a *App) Initialize() error {
 
 	a.initAuth()
 
+	if err := a.initTLS(); err != nil {
+		return fmt.Errorf("failed to initialize TLS: %w", err)
+	}
+
 	if err := a.initRouter(); err != nil {
 		return fmt.Errorf("failed to initialize router: %w", err)
 	}
 
+	a.initReload()
+	return nil
+}
+
+// initTLS 配置了证书时加载证书并监听证书文件，证书轮换后新连接自动使用新证书
+func (a *App) initTLS() error {
+	if !a.config.Server.TLS.Enabled() {
+		return nil
+	}
+	certs, err := tlsconfig.New(&a.config.Server.TLS)
+	if err != nil {
+		return err
+	}
+	a.certs = certs
+	a.goWorker(certs.Run)
+	return nil
+}
+
+// initReload 订阅可重新加载的配置，修改后无需重启、不断开已有连接即可生效
+func (a *App) initReload() {
+	a.reloader.Subscribe(func(cfg *config.Config) {
+		a.logLevel.Set(cfg.Log.SlogLevel())
+		a.cache.UpdatePolicies(&cfg.Redis)
+		a.cluster.SetQueryLogging(&cfg.Database)
+	})
+	a.goWorker(a.reloader.Run)
+}
+
+// initHealth 注册就绪检查：各分片的连接和迁移版本、缓存连接，开启发件箱时检查事件积压
+func (a *App) initHealth(outboxRepo *repository.OutboxRepository) error {
+	a.health = health.New(&a.config.Health)
+	for i, db := range a.cluster.Shards() {
+		sqlDB, err := db.Primary().DB()
+		if err != nil {
+			return err
+		}
+		migrator, err := database.NewMigrator(db.Primary())
+		if err != nil {
+			return err
+		}
+		suffix := ""
+		if a.cluster.Len() > 1 {
+			suffix = fmt.Sprintf("_shard_%d", i)
+		}
+		a.health.Register(
+			health.Database("database"+suffix, sqlDB),
+			health.Migrations("migrations"+suffix, migrator),
+		)
+	}
+	a.health.Register(health.Ping("cache", a.cache))
+	if maxLag := a.config.Health.GetOutboxMaxLag(); a.config.Outbox.Enabled && maxLag > 0 {
+		a.health.Register(health.OutboxLag(outboxRepo, maxLag))
+	}
 	return nil
 }
 
+// grpcHealthInterval gRPC 标准健康检查状态的更新间隔
+const grpcHealthInterval = 5 * time.Second
+
+// initGRPC 创建 gRPC 服务，与 HTTP 服务共用订单和认证服务，启用 HTTPS 时使用相同的证书
+func (a *App) initGRPC(orderService *service.OrderService) {
+	var creds credentials.TransportCredentials
+	if a.certs != nil {
+		creds = credentials.NewTLS(a.certs.ServerConfig("h2"))
+	}
+	a.grpc = grpcserver.New(&a.config.GRPC, orderService, a.authService, creds)
+	a.goWorker(func(ctx context.Context) {
+		a.grpc.WatchReadiness(ctx, func(ctx context.Context) bool {
+			return a.health.Ready(ctx).Ready()
+		}, grpcHealthInterval)
+	})
+}
+
+// goWorker 在后台运行任务，关闭时取消 ctx 并等待任务退出
+func (a *App) goWorker(run func(ctx context.Context)) {
+	a.workers.Add(1)
+	go func() {
+		defer a.workers.Done()
+		run(a.ctx)
+	}()
+}
+
 func (a *App) initDatabase() error {
-	db, err := database.NewDatabase(&a.config.Database)
+	cluster, err := database.NewCluster(&a.config.Database)
+	if err != nil {
+		return err
+	}
+	a.cluster = cluster
+
+	for i, db := range cluster.Shards() {
+		if err := a.registerDBMetrics(db); err != nil {
+			return err
+		}
+		if err := a.checkMigrations(i, db); err != nil {
+			return err
+		}
+	}
+	return cluster.CheckTopology(a.ctx)
+}
+
+// registerDBMetrics 注册分片主库和只读副本的连接池指标
+func (a *App) registerDBMetrics(db *database.Database) error {
+	name := db.Name()
+	sqlDB, err := db.Primary().DB()
+	if err != nil {
+		return err
+	}
+	if err := metrics.RegisterDBStats(sqlDB, name); err != nil {
+		return fmt.Errorf("failed to register database metrics: %w", err)
+	}
+	for i, replica := range db.Replicas() {
+		if err := metrics.RegisterDBStats(replica, fmt.Sprintf("%s_replica_%d", name, i+1)); err != nil {
+			return fmt.Errorf("failed to register replica metrics: %w", err)
+		}
+	}
+	return nil
+}
+
+// checkMigrations 启动时检查分片的迁移状态，开启 auto_migrate 时自动执行
+func (a *App) checkMigrations(shard int, db *database.Database) error {
+	migrator, err := database.NewMigrator(db.Primary())
+	if err != nil {
+		return err
+	}
+
+	if a.config.Database.AutoMigrate {
+		applied, err := migrator.Up(a.ctx)
+		if err != nil {
+			return fmt.Errorf("failed to migrate shard %d: %w", shard, err)
+		}
+		for _, m := range applied {
+			log.Printf("Applied migration %d_%s on shard %d", m.Version, m.Name, shard)
+		}
+		return nil
+	}
+
+	pending, err := migrator.Pending(a.ctx)
 	if err != nil {
 		return err
 	}
-	a.db = db
+	if pending > 0 {
+		log.Printf("Shard %d has %d pending migrations, run `migrate up` to apply them", shard, pending)
+	}
 	return nil
 }
 
 func (a *App) initCache() error {
-	cache, err := cache.NewCache(&a.config.Redis)
+	cache, err := cache.New(&a.config.Redis)
 	if err != nil {
 		return err
 	}
@@ -66,32 +255,187 @@ func (a *App) initCache() error {
 }
 
 func (a *App) initAuth() {
-	a.authService = auth.NewAuthService(a.config)
+	a.authService = auth.NewAuthService(a.config, repository.NewUserRepository(a.cluster))
+}
+
+// newOrderService 创建订单仓储和服务，HTTP 服务和 seed 命令共用
+func (a *App) newOrderService() (*repository.OrderRepository, *repository.OutboxRepository, *service.OrderService, error) {
+	orderRepo, err := repository.NewOrderRepository(a.cluster, a.cache, a.config.Redis.WriteStrategy, a.config.Order.GetNumberPrefix())
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	outboxRepo := repository.NewOutboxRepository(a.cluster)
+	// 关闭发件箱时不记录事件，避免事件表无限增长
+	var events service.EventStore
+	if a.config.Outbox.Enabled {
+		events = outboxRepo
+	}
+	return orderRepo, outboxRepo, service.NewOrderService(orderRepo, repository.NewTxManager(a.cluster), events), nil
 }
 
 func (a *App) initRouter() error {
-	orderRepo := repository.NewOrderRepository(a.db.DB, a.cache)
-	orderService := service.NewOrderService(orderRepo)
+	orderRepo, outboxRepo, orderService, err := a.newOrderService()
+	if err != nil {
+		return err
+	}
+	publisher, err := outbox.NewPublisher(&a.config.Outbox)
+	if err != nil {
+		return err
+	}
+
+	cacheService := service.NewCacheService(orderRepo, a.cache, &a.config.Redis)
+	checker := service.NewConsistencyChecker(orderRepo, a.cache, &a.config.Redis)
+	relay := service.NewOutboxRelay(outboxRepo, publisher, &a.config.Outbox)
+	retentionJob := service.NewRetentionJob(orderRepo, &a.config.Retention)
+	reportService := service.NewReportService(orderRepo)
 	orderHandler := handler.NewOrderHandler(orderService)
 	authHandler := handler.NewAuthHandler(a.authService)
+	cacheHandler := handler.NewCacheHandler(cacheService, checker)
+	diagnosticsHandler := handler.NewDiagnosticsHandler(a.cluster)
+	outboxHandler := handler.NewOutboxHandler(relay)
+	retentionHandler := handler.NewRetentionHandler(retentionJob)
+	reportHandler := handler.NewReportHandler(reportService)
+	if err := a.initHealth(outboxRepo); err != nil {
+		return err
+	}
+	healthHandler := handler.NewHealthHandler(a.health, a.authService)
+	if a.config.GRPC.Enabled() {
+		a.initGRPC(orderService)
+	}
+
+	a.router = router.SetupRouter(router.Deps{
+		OrderHandler:       orderHandler,
+		AuthHandler:        authHandler,
+		CacheHandler:       cacheHandler,
+		DiagnosticsHandler: diagnosticsHandler,
+		OutboxHandler:      outboxHandler,
+		RetentionHandler:   retentionHandler,
+		ReportHandler:      reportHandler,
+		HealthHandler:      healthHandler,
+		DocsHandler:        handler.NewDocsHandler(),
+		AuthService:        a.authService,
+	})
+	// 规范与路由不一致时只记录日志，不影响启动，CI 中由 openapi check 检查
+	if err := docs.CheckRoutes(a.router.Routes()); err != nil {
+		log.Printf("Warning: %v", err)
+	}
 
-	a.router = router.SetupRouter(orderHandler, authHandler, a.authService)
+	a.goWorker(cacheService.WarmupOnStartup)
+	a.goWorker(checker.Run)
+	a.goWorker(relay.Run)
+	a.goWorker(retentionJob.Run)
 	return nil
 }
 
+// Run 启动HTTP服务，收到 SIGINT/SIGTERM 后优雅关闭
 func (a *App) Run() error {
-	log.Printf("Server starting on port %s", a.config.Server.Port)
-	return a.router.Run(":" + a.config.Server.Port)
+	a.server = &http.Server{
+		Addr:         ":" + a.config.Server.Port,
+		Handler:      a.router,
+		ReadTimeout:  time.Duration(a.config.Server.ReadTimeout) * time.Second,
+		WriteTimeout: time.Duration(a.config.Server.WriteTimeout) * time.Second,
+	}
+	if a.certs != nil {
+		// 证书由 TLSConfig 在握手时提供，HTTP/2 通过 ALPN 协商
+		a.server.TLSConfig = a.certs.TLSConfig()
+	}
+
+	ctx, stop := signal.NotifyContext(a.ctx, syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	serveErr := make(chan error, 2)
+	go func() {
+		if a.certs != nil {
+			log.Printf("Server starting on port %s (TLS)", a.config.Server.Port)
+			serveErr <- a.server.ListenAndServeTLS("", "")
+			return
+		}
+		log.Printf("Server starting on port %s", a.config.Server.Port)
+		serveErr <- a.server.ListenAndServe()
+	}()
+	if a.grpc != nil {
+		go func() {
+			serveErr <- a.grpc.Serve()
+		}()
+	}
+
+	select {
+	case err := <-serveErr:
+		// 端口被占用等启动失败的情况，释放已建立的连接后返回
+		a.Shutdown()
+		return err
+	case <-ctx.Done():
+		// 恢复默认的信号处理，关闭过程中再次收到信号时直接退出
+		stop()
+		log.Printf("Received shutdown signal")
+	}
+
+	a.health.SetDraining()
+
+	if delay := time.Duration(a.config.Server.ShutdownDelay) * time.Second; delay > 0 {
+		log.Printf("Waiting %s for load balancers to remove this instance", delay)
+		time.Sleep(delay)
+	}
+	return a.Shutdown()
 }
 
+// Shutdown 按顺序关闭服务：停止接收新请求并等待处理中的请求完成，停止后台任务，最后关闭数据库和缓存连接
+// 整个过程不超过 shutdown_timeout，超时后未完成的请求和任务被放弃
 func (a *App) Shutdown() error {
-	if err := a.db.Close(); err != nil {
-		log.Printf("Error closing database connection: %v", err)
+	if a.health != nil {
+		a.health.SetDraining()
 	}
 
-	if err := a.cache.Close(); err != nil {
-		log.Printf("Error closing cache connection: %v", err)
+	timeout := a.config.Server.GetShutdownTimeout()
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var errs []error
+	if a.server != nil {
+		log.Printf("Draining in-flight requests (timeout %s)", timeout)
+		if err := a.server.Shutdown(ctx); err != nil {
+			log.Printf("Error draining requests: %v", err)
+			errs = append(errs, err)
+		}
 	}
 
-	return nil
+	if a.grpc != nil {
+		log.Printf("Draining in-flight gRPC calls")
+		if err := a.grpc.Shutdown(ctx); err != nil {
+			log.Printf("Error draining gRPC calls: %v", err)
+			errs = append(errs, err)
+		}
+	}
+
+	a.cancel()
+	done := make(chan struct{})
+	go func() {
+		a.workers.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		log.Printf("Timed out waiting for background workers to stop")
+		errs = append(errs, ctx.Err())
+	}
+
+	if a.cluster != nil {
+		if err := a.cluster.Close(); err != nil {
+			log.Printf("Error closing database connection: %v", err)
+			errs = append(errs, err)
+		}
+	}
+
+	if a.cache != nil {
+		if err := a.cache.Close(); err != nil {
+			log.Printf("Error closing cache connection: %v", err)
+			errs = append(errs, err)
+		}
+	}
+
+	if a.server != nil {
+		log.Printf("Server stopped")
+	}
+	return errors.Join(errs...)
 }
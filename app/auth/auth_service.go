@@ -1,35 +1,143 @@
 package auth
 
 import (
+	"context"
 	"errors"
 	"order_api/config"
+	appErrors "order_api/errors"
+	"order_api/model"
+	"order_api/repository"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
 )
 
 var (
 	ErrInvalidCredentials = errors.New("invalid credentials")
 )
 
+// dummyHash 用户名不存在时用于比较的摘要，使响应时间与密码错误时一致，避免探测用户名
+var dummyHash, _ = bcrypt.GenerateFromPassword([]byte("order_api"), bcrypt.DefaultCost)
+
 type AuthService struct {
 	jwtService *JWTService
+	users      *repository.UserRepository
 }
 
-func NewAuthService(config *config.Config) *AuthService {
+func NewAuthService(config *config.Config, users *repository.UserRepository) *AuthService {
 	return &AuthService{
 		jwtService: NewJWTService(config),
+		users:      users,
 	}
 }
 
-// Login 处理用户登录
-func (s *AuthService) Login(username, password string) (string, error) {
-	// TODO: 实现实际的用户验证逻辑
-	// 这里仅作示例，实际应用中需要查询数据库验证用户
-	if username == "admin" && password == "admin123" {
-		return s.jwtService.GenerateToken("admin-user-id", "admin")
+// Login 处理用户登录，账号不存在、已禁用或密码错误时统一返回 ErrInvalidCredentials
+func (s *AuthService) Login(ctx context.Context, username, password string) (string, error) {
+	user, err := s.users.GetByUsername(ctx, username)
+	if errors.Is(err, appErrors.ErrUserNotFound) {
+		bcrypt.CompareHashAndPassword(dummyHash, []byte(password))
+		return "", ErrInvalidCredentials
+	}
+	if err != nil {
+		return "", err
+	}
+	if bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)) != nil || user.Disabled {
+		return "", ErrInvalidCredentials
 	}
-	return "", ErrInvalidCredentials
+	return s.jwtService.GenerateToken(user.ID, user.Role)
 }
 
 // ValidateToken 验证令牌
 func (s *AuthService) ValidateToken(token string) (*Claims, error) {
 	return s.jwtService.ValidateToken(token)
 }
+
+// ValidateAPIKey 验证 API 密钥，返回所属用户的身份，密钥不存在、已过期或用户已禁用时返回 ErrInvalidToken
+func (s *AuthService) ValidateAPIKey(ctx context.Context, key string) (*Claims, error) {
+	apiKey, err := s.users.GetAPIKeyByHash(ctx, hashAPIKey(key))
+	if errors.Is(err, appErrors.ErrUnauthorized) {
+		return nil, ErrInvalidToken
+	}
+	if err != nil {
+		return nil, err
+	}
+	if apiKey.ExpiresAt != nil && time.Now().After(*apiKey.ExpiresAt) {
+		return nil, ErrExpiredToken
+	}
+	user, err := s.users.GetByID(ctx, apiKey.UserID)
+	if errors.Is(err, appErrors.ErrUserNotFound) {
+		return nil, ErrInvalidToken
+	}
+	if err != nil {
+		return nil, err
+	}
+	if user.Disabled {
+		return nil, ErrInvalidToken
+	}
+	return &Claims{UserID: user.ID, Role: user.Role}, nil
+}
+
+// CreateUser 创建账号，密码以 bcrypt 摘要保存
+func (s *AuthService) CreateUser(ctx context.Context, username, password, role string) (*model.User, error) {
+	if !model.ValidRole(role) {
+		return nil, appErrors.ErrInvalidRole
+	}
+	if username == "" || password == "" {
+		return nil, appErrors.New("用户名和密码不能为空")
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+	user := &model.User{
+		ID:           uuid.New().String(),
+		Username:     username,
+		PasswordHash: string(hash),
+		Role:         role,
+	}
+	if err := s.users.Create(ctx, user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// SetDisabled 禁用或启用账号，禁用后不能登录，API 密钥立即失效，已签发的令牌在过期前仍然有效
+func (s *AuthService) SetDisabled(ctx context.Context, username string, disabled bool) error {
+	return s.users.Update(ctx, username, map[string]interface{}{"disabled": disabled})
+}
+
+// SetRole 修改账号角色，对之后签发的令牌和全部 API 密钥生效
+func (s *AuthService) SetRole(ctx context.Context, username, role string) error {
+	if !model.ValidRole(role) {
+		return appErrors.ErrInvalidRole
+	}
+	return s.users.Update(ctx, username, map[string]interface{}{"role": role})
+}
+
+// CreateAPIKey 为账号创建 API 密钥，ttl 为0表示不过期，返回的明文密钥只能在此时获取
+func (s *AuthService) CreateAPIKey(ctx context.Context, username, name string, ttl time.Duration) (string, *model.APIKey, error) {
+	user, err := s.users.GetByUsername(ctx, username)
+	if err != nil {
+		return "", nil, err
+	}
+	key, err := generateAPIKey()
+	if err != nil {
+		return "", nil, err
+	}
+	apiKey := &model.APIKey{
+		ID:      uuid.New().String(),
+		UserID:  user.ID,
+		Name:    name,
+		Prefix:  key[:len(apiKeyPrefix)+6],
+		KeyHash: hashAPIKey(key),
+	}
+	if ttl > 0 {
+		expiresAt := time.Now().Add(ttl)
+		apiKey.ExpiresAt = &expiresAt
+	}
+	if err := s.users.CreateAPIKey(ctx, apiKey); err != nil {
+		return "", nil, err
+	}
+	return key, apiKey, nil
+}
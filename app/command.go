@@ -0,0 +1,39 @@
+package app
+
+import (
+	"flag"
+	"fmt"
+	"io"
+)
+
+// newFlagSet 创建子命令的参数集，解析失败时由调用方返回错误
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	return fs
+}
+
+// parseArgs 解析子命令参数，参数和位置参数可以任意顺序出现，如 user create alice --role admin
+func parseArgs(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, fmt.Errorf("%s: %w", fs.Name(), err)
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// initCommand 为管理命令连接数据库，不启动后台任务和HTTP服务
+func (a *App) initCommand() error {
+	if err := a.initDatabase(); err != nil {
+		return fmt.Errorf("failed to initialize database: %w", err)
+	}
+	a.initAuth()
+	return nil
+}
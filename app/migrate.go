@@ -0,0 +1,97 @@
+package app
+
+import (
+	"fmt"
+	"order_api/database"
+	"os"
+	"strconv"
+	"text/tabwriter"
+)
+
+// Migrate 执行数据库迁移命令：up、down [N]、status、force VERSION
+// 配置了分片时依次在每个分片上执行
+func (a *App) Migrate(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: migrate up|down [N]|status|force VERSION")
+	}
+
+	cluster, err := database.NewCluster(&a.config.Database)
+	if err != nil {
+		return fmt.Errorf("failed to initialize database: %w", err)
+	}
+	defer cluster.Close()
+
+	for i, db := range cluster.Shards() {
+		if cluster.Len() > 1 {
+			fmt.Printf("shard %d:\n", i)
+		}
+		migrator, err := database.NewMigrator(db.Primary())
+		if err != nil {
+			return err
+		}
+		if err := a.migrate(migrator, args); err != nil {
+			return fmt.Errorf("shard %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// migrate 在单个数据库上执行迁移命令
+func (a *App) migrate(migrator *database.Migrator, args []string) error {
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up(a.ctx)
+		for _, m := range applied {
+			fmt.Printf("applied %d_%s\n", m.Version, m.Name)
+		}
+		if err == nil && len(applied) == 0 {
+			fmt.Println("no pending migrations")
+		}
+		return err
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			var err error
+			if steps, err = strconv.Atoi(args[1]); err != nil || steps <= 0 {
+				return fmt.Errorf("invalid number of steps: %s", args[1])
+			}
+		}
+		reverted, err := migrator.Down(a.ctx, steps)
+		for _, m := range reverted {
+			fmt.Printf("reverted %d_%s\n", m.Version, m.Name)
+		}
+		return err
+	case "status":
+		status, err := migrator.Status(a.ctx)
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tSTATUS\tAPPLIED AT")
+		for _, s := range status {
+			state, appliedAt := "pending", ""
+			switch {
+			case s.Dirty:
+				state = "dirty"
+			case s.Applied:
+				state = "applied"
+			}
+			if s.AppliedAt != nil {
+				appliedAt = s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", s.Version, s.Name, state, appliedAt)
+		}
+		return w.Flush()
+	case "force":
+		if len(args) < 2 {
+			return fmt.Errorf("usage: migrate force VERSION")
+		}
+		version, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid version: %s", args[1])
+		}
+		return migrator.Force(a.ctx, version)
+	default:
+		return fmt.Errorf("unknown migrate command: %s", args[0])
+	}
+}
@@ -0,0 +1,66 @@
+package app
+
+import (
+	"fmt"
+	"math/rand"
+	"order_api/model"
+)
+
+// seedTransitions 订单创建后随机选取的状态流转，与接口一样逐步更新并记录事件
+var seedTransitions = [][]string{
+	{},
+	{model.StatusPaid},
+	{model.StatusPaid, model.StatusShipped},
+	{model.StatusPaid, model.StatusShipped, model.StatusDelivered},
+	{model.StatusCancelled},
+}
+
+// Seed 生成测试订单，订单通过订单服务创建，与接口创建的订单一样写入分片、订单号和发件箱事件
+func (a *App) Seed(args []string) error {
+	fs := newFlagSet("seed")
+	orders := fs.Int("orders", 0, "生成的订单数量")
+	users := fs.Int("users", 10, "订单分布的用户数量，用户ID为 seed-user-N")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) > 0 || *orders <= 0 || *users <= 0 {
+		return fmt.Errorf("usage: seed --orders N [--users M]")
+	}
+
+	if err := a.initDatabase(); err != nil {
+		return fmt.Errorf("failed to initialize database: %w", err)
+	}
+	if err := a.initCache(); err != nil {
+		return fmt.Errorf("failed to initialize cache: %w", err)
+	}
+	_, _, orderService, err := a.newOrderService()
+	if err != nil {
+		return err
+	}
+
+	for i := 0; i < *orders; i++ {
+		order := &model.Order{
+			UserID: fmt.Sprintf("seed-user-%d", rand.Intn(*users)+1),
+			Status: model.StatusPending,
+		}
+		for j := rand.Intn(3) + 1; j > 0; j-- {
+			order.Items = append(order.Items, model.OrderItem{
+				ProductID: fmt.Sprintf("seed-product-%d", rand.Intn(100)+1),
+				Quantity:  rand.Intn(5) + 1,
+				Price:     float64(rand.Intn(10000)+100) / 100,
+			})
+		}
+		order.CalculateAmount()
+		if err := orderService.CreateOrder(a.ctx, order); err != nil {
+			return fmt.Errorf("created %d orders, then failed: %w", i, err)
+		}
+		for _, status := range seedTransitions[rand.Intn(len(seedTransitions))] {
+			if err := orderService.UpdateOrderStatus(a.ctx, order.ID, order.UserID, status); err != nil {
+				return fmt.Errorf("created %d orders, then failed: %w", i+1, err)
+			}
+		}
+	}
+	fmt.Printf("created %d orders for %d users\n", *orders, *users)
+	return nil
+}
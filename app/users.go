@@ -0,0 +1,102 @@
+package app
+
+import (
+	"bufio"
+	"fmt"
+	"order_api/model"
+	"os"
+	"strings"
+	"time"
+)
+
+// Users 执行账号管理命令：user create|disable|enable|set-role
+func (a *App) Users(args []string) error {
+	usage := fmt.Errorf("usage: user create USERNAME [--password PASSWORD] [--role admin|user] | disable USERNAME | enable USERNAME | set-role USERNAME ROLE")
+	if len(args) == 0 {
+		return usage
+	}
+
+	fs := newFlagSet("user " + args[0])
+	password := fs.String("password", "", "密码，为空时从标准输入读取一行")
+	role := fs.String("role", model.RoleUser, "角色：admin/user")
+	positional, err := parseArgs(fs, args[1:])
+	if err != nil {
+		return err
+	}
+	if err := a.initCommand(); err != nil {
+		return err
+	}
+
+	switch {
+	case args[0] == "create" && len(positional) == 1:
+		if *password == "" {
+			if *password, err = readPassword(); err != nil {
+				return err
+			}
+		}
+		user, err := a.authService.CreateUser(a.ctx, positional[0], *password, *role)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("created user %s (id %s, role %s)\n", user.Username, user.ID, user.Role)
+		return nil
+	case args[0] == "disable" && len(positional) == 1, args[0] == "enable" && len(positional) == 1:
+		if err := a.authService.SetDisabled(a.ctx, positional[0], args[0] == "disable"); err != nil {
+			return err
+		}
+		fmt.Printf("%sd user %s\n", args[0], positional[0])
+		return nil
+	case args[0] == "set-role" && len(positional) == 2:
+		if err := a.authService.SetRole(a.ctx, positional[0], positional[1]); err != nil {
+			return err
+		}
+		fmt.Printf("set role of user %s to %s\n", positional[0], positional[1])
+		return nil
+	default:
+		return usage
+	}
+}
+
+// APIKeys 执行 API 密钥命令：apikey create
+func (a *App) APIKeys(args []string) error {
+	usage := fmt.Errorf("usage: apikey create USERNAME [--name NAME] [--expires DURATION]")
+	if len(args) == 0 || args[0] != "create" {
+		return usage
+	}
+
+	fs := newFlagSet("apikey create")
+	name := fs.String("name", "default", "密钥名称，用于辨认用途")
+	expires := fs.Duration("expires", 0, "有效期，如 720h，0表示不过期")
+	positional, err := parseArgs(fs, args[1:])
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return usage
+	}
+	if err := a.initCommand(); err != nil {
+		return err
+	}
+
+	key, apiKey, err := a.authService.CreateAPIKey(a.ctx, positional[0], *name, *expires)
+	if err != nil {
+		return err
+	}
+	expiry := "never"
+	if apiKey.ExpiresAt != nil {
+		expiry = apiKey.ExpiresAt.Format(time.RFC3339)
+	}
+	fmt.Fprintf(os.Stderr, "created API key %s for user %s (id %s, expires %s), it will not be shown again\n", apiKey.Name, positional[0], apiKey.ID, expiry)
+	fmt.Println(key)
+	return nil
+}
+
+// readPassword 从标准输入读取密码，便于通过管道传入而不出现在命令行历史中
+func readPassword() (string, error) {
+	fmt.Fprint(os.Stderr, "Password: ")
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return "", fmt.Errorf("failed to read password: %w", err)
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
@@ -0,0 +1,196 @@
+package cache
+
+import (
+	"context"
+	"order_api/errors"
+	"order_api/model"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// EntryInfo 订单缓存条目的诊断信息
+type EntryInfo struct {
+	OrderID        string       `json:"order_id"`
+	Key            string       `json:"key"`
+	Local          bool         `json:"local"`                      // 是否存在于本节点的本地缓存
+	LocalExpiresAt *time.Time   `json:"local_expires_at,omitempty"` // 本地缓存过期时间
+	Remote         bool         `json:"remote"`                     // 是否存在于Redis
+	RemoteTTL      int64        `json:"remote_ttl_seconds,omitempty"`
+	Size           int          `json:"size,omitempty"` // Redis中存储的字节数
+	Codec          string       `json:"codec,omitempty"`
+	Compression    string       `json:"compression,omitempty"`
+	Order          *model.Order `json:"order,omitempty"`
+	DecodeError    string       `json:"decode_error,omitempty"`
+}
+
+// InspectOrder 查看订单在各级缓存中的状态
+func (c *Cache) InspectOrder(ctx context.Context, orderID string) (*EntryInfo, error) {
+	key := c.keys.order(orderID)
+	info := &EntryInfo{OrderID: orderID, Key: key}
+
+	if value, ok := c.localCache.Load(orderID); ok {
+		entry := value.(localEntry)
+		info.Local = true
+		info.LocalExpiresAt = &entry.expiresAt
+		info.Order = entry.order
+	}
+
+	pipe := c.redis.Pipeline()
+	getCmd := pipe.Get(ctx, key)
+	ttlCmd := pipe.TTL(ctx, key)
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return nil, errors.Wrap(err, "读取缓存失败")
+	}
+
+	data, err := getCmd.Bytes()
+	if err == redis.Nil {
+		return info, nil
+	}
+
+	info.Remote = true
+	info.RemoteTTL = int64(ttlCmd.Val() / time.Second)
+	info.Size = len(data)
+	info.Codec, info.Compression = c.serializer.describe(data)
+
+	var order model.Order
+	if err := c.serializer.decode(data, &order); err != nil {
+		info.DecodeError = err.Error()
+	} else {
+		info.Order = &order
+	}
+	return info, nil
+}
+
+// EvictOrder 删除单个订单缓存，并通知所有节点清理本地缓存
+func (c *Cache) EvictOrder(ctx context.Context, orderID string) error {
+	info, err := c.InspectOrder(ctx, orderID)
+	if err != nil {
+		return err
+	}
+
+	pipe := c.redis.Pipeline()
+	pipe.Del(ctx, info.Key)
+	if info.Order != nil {
+		pipe.SRem(ctx, c.keys.userOrders(info.Order.UserID), orderID)
+	}
+	c.publishEvict(ctx, pipe, orderID)
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return errors.Wrap(err, "缓存删除失败")
+	}
+	return nil
+}
+
+// EvictUser 删除用户的全部订单缓存，返回删除的订单数量
+func (c *Cache) EvictUser(ctx context.Context, userID string) (int, error) {
+	userOrdersKey := c.keys.userOrders(userID)
+	orderIDs, err := c.redis.SMembers(ctx, userOrdersKey).Result()
+	if err != nil {
+		return 0, errors.Wrap(err, "读取用户订单缓存失败")
+	}
+
+	pipe := c.redis.Pipeline()
+	for _, id := range orderIDs {
+		pipe.Del(ctx, c.keys.order(id))
+	}
+	pipe.Del(ctx, userOrdersKey)
+	if len(orderIDs) > 0 {
+		c.publishEvict(ctx, pipe, orderIDs...)
+	}
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, errors.Wrap(err, "缓存删除失败")
+	}
+	return len(orderIDs), nil
+}
+
+// FlushLocal 清空所有节点的本地缓存，Redis中的数据保留
+func (c *Cache) FlushLocal(ctx context.Context) error {
+	pipe := c.redis.Pipeline()
+	c.publish(ctx, pipe, invalidation{Op: invalidateFlush})
+	if _, err := pipe.Exec(ctx); err != nil {
+		return errors.Wrap(err, "广播本地缓存清理失败")
+	}
+	return nil
+}
+
+// SampleOrders 抽样读取Redis中的订单缓存，供一致性巡检使用
+func (c *Cache) SampleOrders(ctx context.Context, n int) ([]*model.Order, error) {
+	keys, err := c.sampleKeys(ctx, n)
+	if err != nil {
+		return nil, errors.Wrap(err, "扫描缓存失败")
+	}
+	if len(keys) == 0 {
+		return nil, nil
+	}
+
+	// Cluster 模式下键可能分布在不同槽位，使用管道逐个读取而不是 MGET
+	pipe := c.redis.Pipeline()
+	cmds := make([]*redis.StringCmd, len(keys))
+	for i, key := range keys {
+		cmds[i] = pipe.Get(ctx, key)
+	}
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return nil, errors.Wrap(err, "读取缓存失败")
+	}
+
+	orders := make([]*model.Order, 0, len(cmds))
+	for i, cmd := range cmds {
+		data, err := cmd.Bytes()
+		if err != nil {
+			continue
+		}
+		var order model.Order
+		if err := c.serializer.decode(data, &order); err != nil {
+			// 无法解码的条目同样视为不一致，只保留ID供巡检处理
+			order = model.Order{ID: c.keys.orderIDFromKey(keys[i])}
+		}
+		orders = append(orders, &order)
+	}
+	return orders, nil
+}
+
+// sampleKeys 扫描订单缓存键，单节点和Sentinel模式下从上次的游标继续
+func (c *Cache) sampleKeys(ctx context.Context, n int) ([]string, error) {
+	pattern := c.keys.orderPattern()
+
+	if cluster, ok := c.redis.(*redis.ClusterClient); ok {
+		var (
+			mu   sync.Mutex
+			keys []string
+		)
+		err := cluster.ForEachMaster(ctx, func(ctx context.Context, master *redis.Client) error {
+			found, _, err := master.Scan(ctx, 0, pattern, int64(n)).Result()
+			mu.Lock()
+			keys = append(keys, found...)
+			mu.Unlock()
+			return err
+		})
+		if len(keys) > n {
+			keys = keys[:n]
+		}
+		return keys, err
+	}
+
+	c.sampleMu.Lock()
+	defer c.sampleMu.Unlock()
+
+	var keys []string
+	for len(keys) < n {
+		found, cursor, err := c.redis.Scan(ctx, c.sampleCursor, pattern, int64(n)).Result()
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, found...)
+		c.sampleCursor = cursor
+		if cursor == 0 {
+			break
+		}
+	}
+	if len(keys) > n {
+		keys = keys[:n]
+	}
+	return keys, nil
+}
@@ -2,60 +2,117 @@ package cache
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
+	"log"
 	"order_api/config"
 	"order_api/errors"
 	"order_api/model"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
 )
 
+// Store 缓存后端接口，满足 repository.Cache 并支持关闭连接
+type Store interface {
+	GetOrder(ctx context.Context, orderID string) (*model.Order, error)
+	SetOrder(ctx context.Context, order *model.Order) error
+	DeleteOrder(ctx context.Context, orderID string, userID string) error
+
+	// 运维管理接口
+	InspectOrder(ctx context.Context, orderID string) (*EntryInfo, error)
+	EvictOrder(ctx context.Context, orderID string) error
+	EvictUser(ctx context.Context, userID string) (int, error)
+	FlushLocal(ctx context.Context) error
+	SampleOrders(ctx context.Context, n int) ([]*model.Order, error)
+
+	// UpdatePolicies 在运行时替换过期策略，只影响之后写入的缓存
+	UpdatePolicies(cfg *config.RedisConfig)
+
+	// Ping 检查缓存后端是否可用
+	Ping(ctx context.Context) error
+
+	Close() error
+}
+
+// New 根据配置中的模式创建缓存后端
+func New(cfg *config.RedisConfig) (Store, error) {
+	if cfg.Mode == config.RedisModeMemory {
+		return NewMemoryCache(cfg), nil
+	}
+	return NewCache(cfg)
+}
+
+// localEntry 本地缓存条目
+type localEntry struct {
+	order     *model.Order
+	expiresAt time.Time
+}
+
+// Cache 本地缓存 + Redis 的二级缓存
 type Cache struct {
-	localCache sync.Map
-	redis      *redis.Client
-	config     *config.Config
+	localCache   sync.Map
+	redis        redis.UniversalClient
+	keys         keyspace
+	serializer   *serializer
+	policies     atomic.Pointer[policies]
+	pubsub       *redis.PubSub
+	nodeID       string // 本节点发出的失效广播的标识，收到自己的广播时不再重复执行
+	sampleCursor uint64 // 一致性巡检的SCAN游标，多次抽样依次覆盖整个键空间
+	sampleMu     sync.Mutex
 }
 
 func NewCache(cfg *config.RedisConfig) (*Cache, error) {
-	client := redis.NewClient(&redis.Options{
-		Addr:         fmt.Sprintf("%s:%s", cfg.Host, cfg.Port),
-		Password:     cfg.Password,
-		DB:           cfg.DB,
-		PoolSize:     cfg.PoolSize,
-		MaxRetries:   cfg.MaxRetries,
-		MinIdleConns: cfg.MaxIdleConns,
-	})
+	serializer, err := newSerializer(cfg)
+	if err != nil {
+		return nil, errors.Wrap(err, "创建缓存序列化器失败")
+	}
+
+	client, err := newRedisClient(cfg)
+	if err != nil {
+		return nil, err
+	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
 	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
 		return nil, fmt.Errorf("Redis连接失败: %w", err)
 	}
 
-	return &Cache{
+	c := &Cache{
 		localCache: sync.Map{},
 		redis:      client,
-	}, nil
+		keys:       newKeyspace(cfg),
+		serializer: serializer,
+		nodeID:     uuid.NewString(),
+	}
+	c.policies.Store(newPolicies(cfg))
+	c.subscribeInvalidations()
+	return c, nil
 }
 
 // GetOrder 获取订单信息
 func (c *Cache) GetOrder(ctx context.Context, orderID string) (*model.Order, error) {
 	// 1. 先查本地缓存
 	if value, ok := c.localCache.Load(orderID); ok {
-		return value.(*model.Order), nil
+		entry := value.(localEntry)
+		if time.Now().Before(entry.expiresAt) {
+			return entry.order, nil
+		}
+		c.localCache.CompareAndDelete(orderID, value)
 	}
 
 	// 2. 查Redis缓存
-	data, err := c.redis.Get(ctx, c.getOrderKey(orderID)).Bytes()
+	data, err := c.redis.Get(ctx, c.keys.order(orderID)).Bytes()
 	if err == nil {
 		var order model.Order
-		if err := json.Unmarshal(data, &order); err == nil {
+		if err := c.serializer.decode(data, &order); err == nil {
 			// 写入本地缓存
-			c.localCache.Store(orderID, &order)
+			c.storeLocal(&order, c.policies.Load().order.localTTL)
 			return &order, nil
 		}
 	}
@@ -63,32 +120,51 @@ func (c *Cache) GetOrder(ctx context.Context, orderID string) (*model.Order, err
 	return nil, errors.New("cache miss")
 }
 
-// SetOrder 将订单信息写入缓存
+// SetOrder 将订单信息写入缓存，并通知其他节点删除该订单的本地缓存
 func (c *Cache) SetOrder(ctx context.Context, order *model.Order) error {
-	data, err := json.Marshal(order)
+	data, err := c.serializer.encode(order)
 	if err != nil {
 		return errors.Wrap(err, "订单序列化失败")
 	}
 
+	policies := c.policies.Load()
+	expiration := policies.order.orderExpiration(order)
+	userOrdersKey := c.keys.userOrders(order.UserID)
+
 	// 使用管道批量执行Redis命令
 	pipe := c.redis.Pipeline()
-	pipe.Set(ctx, c.getOrderKey(order.ID), data, 30*time.Minute)
-	pipe.SAdd(ctx, c.getUserOrdersKey(order.UserID), order.ID)
+	pipe.Set(ctx, c.keys.order(order.ID), data, expiration)
+	pipe.SAdd(ctx, userOrdersKey, order.ID)
+	pipe.Expire(ctx, userOrdersKey, policies.userOrders.expiration())
 
 	if _, err := pipe.Exec(ctx); err != nil {
 		return errors.Wrap(err, "缓存写入失败")
 	}
 
+	// Redis 写入成功后通知其他节点删除旧的本地缓存，它们下次读取时从Redis加载新数据
+	if err := c.publishEvict(ctx, c.redis, order.ID).Err(); err != nil {
+		log.Printf("缓存失效广播失败: %v", err)
+	}
+
 	// 更新本地缓存
-	c.localCache.Store(order.ID, order)
+	c.storeLocal(order, policies.order.localExpiration(expiration))
 	return nil
 }
 
+// storeLocal 写入本地缓存并设置过期时间
+func (c *Cache) storeLocal(order *model.Order, ttl time.Duration) {
+	c.localCache.Store(order.ID, localEntry{
+		order:     order,
+		expiresAt: time.Now().Add(ttl),
+	})
+}
+
 // DeleteOrder 从缓存中删除订单信息
 func (c *Cache) DeleteOrder(ctx context.Context, orderID string, userID string) error {
 	pipe := c.redis.Pipeline()
-	pipe.Del(ctx, c.getOrderKey(orderID))
-	pipe.SRem(ctx, c.getUserOrdersKey(userID), orderID)
+	pipe.Del(ctx, c.keys.order(orderID))
+	pipe.SRem(ctx, c.keys.userOrders(userID), orderID)
+	c.publishEvict(ctx, pipe, orderID)
 
 	if _, err := pipe.Exec(ctx); err != nil {
 		return errors.Wrap(err, "缓存删除失败")
@@ -98,17 +174,20 @@ func (c *Cache) DeleteOrder(ctx context.Context, orderID string, userID string)
 	return nil
 }
 
-// Close 关闭缓存连接
-func (c *Cache) Close() error {
-	return c.redis.Close()
+// UpdatePolicies 替换过期策略，已写入的缓存保持原有的过期时间
+func (c *Cache) UpdatePolicies(cfg *config.RedisConfig) {
+	c.policies.Store(newPolicies(cfg))
 }
 
-// getOrderKey 生成订单缓存键
-func (c *Cache) getOrderKey(orderID string) string {
-	return fmt.Sprintf("order:%s", orderID)
+// Ping 检查Redis连接
+func (c *Cache) Ping(ctx context.Context) error {
+	return c.redis.Ping(ctx).Err()
 }
 
-// getUserOrdersKey 生成用户订单列表缓存键
-func (c *Cache) getUserOrdersKey(userID string) string {
-	return fmt.Sprintf("user:%s:orders", userID)
-}
\ No newline at end of file
+// Close 关闭缓存连接
+func (c *Cache) Close() error {
+	if c.pubsub != nil {
+		c.pubsub.Close()
+	}
+	return c.redis.Close()
+}
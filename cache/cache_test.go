@@ -0,0 +1,88 @@
+package cache
+
+import (
+	"context"
+	"net"
+	"order_api/config"
+	"order_api/model"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// newTestCaches 创建共享同一 Redis 键空间的多个节点，REDIS_ADDR 未设置时跳过测试
+func newTestCaches(t *testing.T, n int) []*Cache {
+	t.Helper()
+	addr := os.Getenv("REDIS_ADDR")
+	if addr == "" {
+		t.Skip("REDIS_ADDR not set")
+	}
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		t.Fatalf("invalid REDIS_ADDR: %v", err)
+	}
+
+	cfg := &config.RedisConfig{Host: host, Port: port, KeyPrefix: "test:" + uuid.NewString() + ":"}
+	caches := make([]*Cache, n)
+	for i := range caches {
+		c, err := NewCache(cfg)
+		if err != nil {
+			t.Fatalf("NewCache: %v", err)
+		}
+		t.Cleanup(func() { c.Close() })
+		caches[i] = c
+	}
+	// 等待全部节点订阅生效，否则早于订阅发出的广播会丢失
+	channel := caches[0].keys.invalidationChannel()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		subs, err := caches[0].redis.PubSubNumSub(context.Background(), channel).Result()
+		if err == nil && subs[channel] >= int64(n) {
+			return caches
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("nodes did not subscribe to %s: %v, %v", channel, subs, err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestSetOrderEvictsOtherNodes(t *testing.T) {
+	caches := newTestCaches(t, 2)
+	writer, reader := caches[0], caches[1]
+	ctx := context.Background()
+
+	order := &model.Order{ID: uuid.NewString(), UserID: "alice", Status: model.StatusPending}
+	if err := writer.SetOrder(ctx, order); err != nil {
+		t.Fatalf("SetOrder: %v", err)
+	}
+	if got, err := reader.GetOrder(ctx, order.ID); err != nil || got.Status != model.StatusPending {
+		t.Fatalf("reader GetOrder = %v, %v", got, err)
+	}
+
+	// reader 的本地缓存中已有旧数据，writer 更新后应收到广播并重新从Redis读取
+	updated := *order
+	updated.Status = model.StatusPaid
+	if err := writer.SetOrder(ctx, &updated); err != nil {
+		t.Fatalf("SetOrder: %v", err)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		got, err := reader.GetOrder(ctx, order.ID)
+		if err == nil && got.Status == model.StatusPaid {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("reader still serves %v, %v after the update", got, err)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	// 自己发出的广播不会删除刚写入的本地缓存
+	time.Sleep(100 * time.Millisecond)
+	if _, ok := writer.localCache.Load(order.ID); !ok {
+		t.Fatal("writer evicted its own fresh local entry")
+	}
+}
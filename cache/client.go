@@ -0,0 +1,45 @@
+package cache
+
+import (
+	"fmt"
+	"order_api/config"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// newRedisClient 根据配置的模式创建单节点、Sentinel 或 Cluster 客户端
+func newRedisClient(cfg *config.RedisConfig) (redis.UniversalClient, error) {
+	switch cfg.Mode {
+	case "", config.RedisModeStandalone:
+		return redis.NewClient(&redis.Options{
+			Addr:         cfg.GetRedisAddr(),
+			Password:     string(cfg.Password),
+			DB:           cfg.DB,
+			PoolSize:     cfg.PoolSize,
+			MaxRetries:   cfg.MaxRetries,
+			MinIdleConns: cfg.MaxIdleConns,
+		}), nil
+	case config.RedisModeSentinel:
+		return redis.NewFailoverClient(&redis.FailoverOptions{
+			MasterName:       cfg.MasterName,
+			SentinelAddrs:    cfg.Addrs,
+			SentinelPassword: string(cfg.SentinelPassword),
+			Password:         string(cfg.Password),
+			DB:               cfg.DB,
+			PoolSize:         cfg.PoolSize,
+			MaxRetries:       cfg.MaxRetries,
+			MinIdleConns:     cfg.MaxIdleConns,
+		}), nil
+	case config.RedisModeCluster:
+		// Cluster 不支持选择DB，管道命令会按槽位自动拆分到各节点
+		return redis.NewClusterClient(&redis.ClusterOptions{
+			Addrs:        cfg.Addrs,
+			Password:     string(cfg.Password),
+			PoolSize:     cfg.PoolSize,
+			MaxRetries:   cfg.MaxRetries,
+			MinIdleConns: cfg.MaxIdleConns,
+		}), nil
+	default:
+		return nil, fmt.Errorf("不支持的Redis模式: %s", cfg.Mode)
+	}
+}
@@ -0,0 +1,297 @@
+package cache
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"order_api/config"
+	"order_api/errors"
+	"sync"
+
+	"github.com/klauspost/compress/snappy"
+	"github.com/klauspost/compress/zstd"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// 缓存条目头部字节布局：
+//
+//	bit 7-5 格式版本，bit 4-2 编解码器ID，bit 1-0 压缩算法ID
+//
+// 读取时只依据头部解码，与本节点配置的编码方式无关，
+// 因此滚动发布期间新旧节点可以互相读取对方写入的缓存。
+const (
+	wireVersion     = 1
+	wireVersionMask = 0xE0
+	codecMask       = 0x1C
+	compressionMask = 0x03
+)
+
+// Codec 订单序列化编解码器
+type Codec interface {
+	ID() byte
+	Name() string
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+// Compressor 压缩算法
+type Compressor interface {
+	ID() byte
+	Name() string
+	Compress(src []byte) ([]byte, error)
+	Decompress(src []byte) ([]byte, error)
+}
+
+var (
+	registryMu  sync.RWMutex
+	codecs      = map[byte]Codec{}
+	compressors = map[byte]Compressor{}
+)
+
+// RegisterCodec 注册编解码器，ID 取值范围 1-7
+func RegisterCodec(c Codec) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	codecs[c.ID()] = c
+}
+
+// RegisterCompressor 注册压缩算法，ID 取值范围 1-3
+func RegisterCompressor(c Compressor) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	compressors[c.ID()] = c
+}
+
+func init() {
+	RegisterCodec(jsonCodec{})
+	RegisterCodec(msgpackCodec{})
+	RegisterCompressor(snappyCompressor{})
+}
+
+var (
+	zstdOnce sync.Once
+	zstdErr  error
+)
+
+// registerZstd 首次创建序列化器时注册 zstd 压缩，编码器或解码器创建失败时返回错误
+// 与配置的压缩方式无关：读取其他节点写入的 zstd 缓存同样需要解码器
+func registerZstd() error {
+	zstdOnce.Do(func() {
+		c, err := newZstdCompressor()
+		if err != nil {
+			zstdErr = err
+			return
+		}
+		RegisterCompressor(c)
+	})
+	return zstdErr
+}
+
+func codecByName(name string) (Codec, error) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	for _, c := range codecs {
+		if c.Name() == name {
+			return c, nil
+		}
+	}
+	return nil, fmt.Errorf("不支持的缓存编码: %s", name)
+}
+
+func compressorByName(name string) (Compressor, error) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	for _, c := range compressors {
+		if c.Name() == name {
+			return c, nil
+		}
+	}
+	return nil, fmt.Errorf("不支持的缓存压缩算法: %s", name)
+}
+
+// serializer 负责缓存条目的编码、压缩和头部处理
+type serializer struct {
+	codec      Codec
+	compressor Compressor // 为空表示不压缩
+	threshold  int        // 超过该字节数才压缩
+}
+
+func newSerializer(cfg *config.RedisConfig) (*serializer, error) {
+	if err := registerZstd(); err != nil {
+		return nil, err
+	}
+
+	name := cfg.Codec
+	if name == "" {
+		name = "json"
+	}
+	codec, err := codecByName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &serializer{codec: codec, threshold: cfg.CompressThreshold}
+	if cfg.Compression != "" && cfg.Compression != "none" {
+		if s.compressor, err = compressorByName(cfg.Compression); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// encode 序列化并按需压缩，返回带头部字节的数据
+func (s *serializer) encode(v interface{}) ([]byte, error) {
+	payload, err := s.codec.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var compression byte
+	if s.compressor != nil && len(payload) > s.threshold {
+		compressed, err := s.compressor.Compress(payload)
+		if err != nil {
+			return nil, err
+		}
+		payload = compressed
+		compression = s.compressor.ID()
+	}
+
+	data := make([]byte, 0, len(payload)+1)
+	data = append(data, wireVersion<<5|s.codec.ID()<<2|compression)
+	return append(data, payload...), nil
+}
+
+// decode 根据头部字节选择解压和解码方式
+func (s *serializer) decode(data []byte, v interface{}) error {
+	if len(data) == 0 {
+		return fmt.Errorf("缓存数据为空")
+	}
+
+	// 兼容引入头部之前写入的纯JSON缓存
+	if data[0] == '{' {
+		return json.Unmarshal(data, v)
+	}
+
+	header := data[0]
+	if header&wireVersionMask>>5 != wireVersion {
+		return fmt.Errorf("未知的缓存格式版本: %d", header>>5)
+	}
+
+	registryMu.RLock()
+	codec, ok := codecs[header&codecMask>>2]
+	compressor, compressed := compressors[header&compressionMask]
+	registryMu.RUnlock()
+	if !ok {
+		return fmt.Errorf("未知的缓存编码: %d", header&codecMask>>2)
+	}
+
+	payload := data[1:]
+	if header&compressionMask != 0 {
+		if !compressed {
+			return fmt.Errorf("未知的缓存压缩算法: %d", header&compressionMask)
+		}
+		var err error
+		if payload, err = compressor.Decompress(payload); err != nil {
+			return err
+		}
+	}
+	return codec.Unmarshal(payload, v)
+}
+
+// describe 返回缓存条目使用的编码和压缩方式名称
+func (s *serializer) describe(data []byte) (codec, compression string) {
+	if len(data) == 0 {
+		return "", ""
+	}
+	if data[0] == '{' {
+		return "json", "none"
+	}
+
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	codec, compression = "unknown", "none"
+	if c, ok := codecs[data[0]&codecMask>>2]; ok {
+		codec = c.Name()
+	}
+	if id := data[0] & compressionMask; id != 0 {
+		compression = "unknown"
+		if c, ok := compressors[id]; ok {
+			compression = c.Name()
+		}
+	}
+	return codec, compression
+}
+
+// jsonCodec JSON编解码
+type jsonCodec struct{}
+
+func (jsonCodec) ID() byte                                   { return 1 }
+func (jsonCodec) Name() string                               { return "json" }
+func (jsonCodec) Marshal(v interface{}) ([]byte, error)      { return json.Marshal(v) }
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error { return json.Unmarshal(data, v) }
+
+// msgpackCodec MessagePack编解码，复用json标签作为字段名
+type msgpackCodec struct{}
+
+func (msgpackCodec) ID() byte     { return 2 }
+func (msgpackCodec) Name() string { return "msgpack" }
+
+func (msgpackCodec) Marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := msgpack.NewEncoder(&buf)
+	enc.SetCustomStructTag("json")
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (msgpackCodec) Unmarshal(data []byte, v interface{}) error {
+	dec := msgpack.NewDecoder(bytes.NewReader(data))
+	dec.SetCustomStructTag("json")
+	return dec.Decode(v)
+}
+
+// snappyCompressor Snappy压缩，速度快、压缩率一般
+type snappyCompressor struct{}
+
+func (snappyCompressor) ID() byte     { return 1 }
+func (snappyCompressor) Name() string { return "snappy" }
+
+func (snappyCompressor) Compress(src []byte) ([]byte, error) {
+	return snappy.Encode(nil, src), nil
+}
+
+func (snappyCompressor) Decompress(src []byte) ([]byte, error) {
+	return snappy.Decode(nil, src)
+}
+
+// zstdCompressor Zstandard压缩，压缩率高，编码器和解码器可并发复用
+type zstdCompressor struct {
+	encoder *zstd.Encoder
+	decoder *zstd.Decoder
+}
+
+func newZstdCompressor() (*zstdCompressor, error) {
+	encoder, err := zstd.NewWriter(nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "创建zstd编码器失败")
+	}
+	decoder, err := zstd.NewReader(nil)
+	if err != nil {
+		encoder.Close()
+		return nil, errors.Wrap(err, "创建zstd解码器失败")
+	}
+	return &zstdCompressor{encoder: encoder, decoder: decoder}, nil
+}
+
+func (*zstdCompressor) ID() byte     { return 2 }
+func (*zstdCompressor) Name() string { return "zstd" }
+
+func (c *zstdCompressor) Compress(src []byte) ([]byte, error) {
+	return c.encoder.EncodeAll(src, nil), nil
+}
+
+func (c *zstdCompressor) Decompress(src []byte) ([]byte, error) {
+	return c.decoder.DecodeAll(src, nil)
+}
@@ -0,0 +1,59 @@
+package cache
+
+import (
+	"order_api/config"
+	"order_api/model"
+	"strings"
+	"testing"
+)
+
+func TestNewZstdCompressor(t *testing.T) {
+	c, err := newZstdCompressor()
+	if err != nil {
+		t.Fatalf("newZstdCompressor: %v", err)
+	}
+	data := []byte(strings.Repeat("order-", 100))
+	compressed, err := c.Compress(data)
+	if err != nil {
+		t.Fatalf("Compress: %v", err)
+	}
+	got, err := c.Decompress(compressed)
+	if err != nil {
+		t.Fatalf("Decompress: %v", err)
+	}
+	if string(got) != string(data) {
+		t.Fatalf("round trip mismatch")
+	}
+}
+
+// TestSerializerDecodesOtherCompression 滚动发布期间其他节点可能使用不同的压缩方式写入缓存
+func TestSerializerDecodesOtherCompression(t *testing.T) {
+	writer, err := newSerializer(&config.RedisConfig{Codec: "msgpack", Compression: "zstd"})
+	if err != nil {
+		t.Fatalf("newSerializer(zstd): %v", err)
+	}
+	reader, err := newSerializer(&config.RedisConfig{Compression: "none"})
+	if err != nil {
+		t.Fatalf("newSerializer(none): %v", err)
+	}
+
+	order := &model.Order{ID: "order-1", UserID: "user-1"}
+	for i := 0; i < 20; i++ {
+		order.Items = append(order.Items, model.OrderItem{OrderID: order.ID, ProductID: "product-1", Quantity: 1, Price: 9.9})
+	}
+	data, err := writer.encode(order)
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	if codec, compression := reader.describe(data); codec != "msgpack" || compression != "zstd" {
+		t.Fatalf("describe = %s/%s, want msgpack/zstd", codec, compression)
+	}
+
+	var got model.Order
+	if err := reader.decode(data, &got); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if got.ID != order.ID || len(got.Items) != len(order.Items) {
+		t.Fatalf("decoded order = %+v", got)
+	}
+}
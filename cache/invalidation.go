@@ -0,0 +1,69 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// 本地缓存失效广播操作
+const (
+	invalidateEvict = "evict" // 删除指定订单的本地缓存
+	invalidateFlush = "flush" // 清空本地缓存
+)
+
+// invalidation 通过Redis发布订阅在所有节点间广播的本地缓存失效消息
+type invalidation struct {
+	Op       string   `json:"op"`
+	OrderIDs []string `json:"order_ids,omitempty"`
+	Origin   string   `json:"origin,omitempty"` // 发出广播的节点
+}
+
+// subscribeInvalidations 订阅失效广播，收到消息后清理本节点的本地缓存
+func (c *Cache) subscribeInvalidations() {
+	c.pubsub = c.redis.Subscribe(context.Background(), c.keys.invalidationChannel())
+	go func() {
+		for msg := range c.pubsub.Channel() {
+			var inv invalidation
+			if err := json.Unmarshal([]byte(msg.Payload), &inv); err != nil {
+				log.Printf("无效的缓存失效消息: %v", err)
+				continue
+			}
+			// 本节点的广播在发出时已经执行，SetOrder 随后写入的新数据不能被删除
+			if inv.Origin == c.nodeID {
+				continue
+			}
+			c.applyInvalidation(inv)
+		}
+	}()
+}
+
+// applyInvalidation 在本节点执行失效操作
+func (c *Cache) applyInvalidation(inv invalidation) {
+	switch inv.Op {
+	case invalidateEvict:
+		for _, id := range inv.OrderIDs {
+			c.localCache.Delete(id)
+		}
+	case invalidateFlush:
+		c.localCache.Range(func(key, _ interface{}) bool {
+			c.localCache.Delete(key)
+			return true
+		})
+	}
+}
+
+// publish 广播失效消息，本节点同时立即执行
+func (c *Cache) publish(ctx context.Context, cmd redis.Cmdable, inv invalidation) *redis.IntCmd {
+	c.applyInvalidation(inv)
+	inv.Origin = c.nodeID
+	payload, _ := json.Marshal(inv)
+	return cmd.Publish(ctx, c.keys.invalidationChannel(), payload)
+}
+
+// publishEvict 广播删除指定订单的本地缓存
+func (c *Cache) publishEvict(ctx context.Context, cmd redis.Cmdable, orderIDs ...string) *redis.IntCmd {
+	return c.publish(ctx, cmd, invalidation{Op: invalidateEvict, OrderIDs: orderIDs})
+}
@@ -0,0 +1,49 @@
+package cache
+
+import (
+	"fmt"
+	"order_api/config"
+	"strings"
+)
+
+// keyspace 负责生成带命名空间和版本号的缓存键
+type keyspace struct {
+	prefix  string
+	version int
+}
+
+func newKeyspace(cfg *config.RedisConfig) keyspace {
+	return keyspace{
+		prefix:  cfg.KeyPrefix,
+		version: cfg.SchemaVersion,
+	}
+}
+
+// order 生成订单缓存键
+// 订单以序列化形式存储，键中带上结构版本号，版本变更后旧数据不会再被读取
+func (k keyspace) order(orderID string) string {
+	if k.version > 0 {
+		return fmt.Sprintf("%sorder:v%d:%s", k.prefix, k.version, orderID)
+	}
+	return fmt.Sprintf("%sorder:%s", k.prefix, orderID)
+}
+
+// orderPattern 匹配当前版本全部订单缓存键的模式
+func (k keyspace) orderPattern() string {
+	return k.order("*")
+}
+
+// orderIDFromKey 从订单缓存键中解析订单ID
+func (k keyspace) orderIDFromKey(key string) string {
+	return strings.TrimPrefix(key, strings.TrimSuffix(k.order("*"), "*"))
+}
+
+// userOrders 生成用户订单列表缓存键
+func (k keyspace) userOrders(userID string) string {
+	return fmt.Sprintf("%suser:%s:orders", k.prefix, userID)
+}
+
+// invalidationChannel 本地缓存失效广播频道
+func (k keyspace) invalidationChannel() string {
+	return fmt.Sprintf("%scache:invalidate", k.prefix)
+}
@@ -0,0 +1,161 @@
+package cache
+
+import (
+	"context"
+	"order_api/config"
+	"order_api/errors"
+	"order_api/model"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// memoryEntry 进程内缓存条目
+type memoryEntry struct {
+	order     *model.Order
+	expiresAt time.Time
+}
+
+// MemoryCache 纯进程内缓存实现，不依赖Redis，适用于本地开发和测试
+type MemoryCache struct {
+	mu         sync.RWMutex
+	orders     map[string]memoryEntry
+	userOrders map[string]map[string]struct{}
+	policies   atomic.Pointer[policies]
+}
+
+// NewMemoryCache 创建进程内缓存
+func NewMemoryCache(cfg *config.RedisConfig) *MemoryCache {
+	c := &MemoryCache{
+		orders:     make(map[string]memoryEntry),
+		userOrders: make(map[string]map[string]struct{}),
+	}
+	c.policies.Store(newPolicies(cfg))
+	return c
+}
+
+// GetOrder 获取订单信息
+func (c *MemoryCache) GetOrder(ctx context.Context, orderID string) (*model.Order, error) {
+	c.mu.RLock()
+	entry, ok := c.orders[orderID]
+	c.mu.RUnlock()
+
+	if !ok || time.Now().After(entry.expiresAt) {
+		return nil, errors.New("cache miss")
+	}
+	return entry.order, nil
+}
+
+// SetOrder 将订单信息写入缓存
+func (c *MemoryCache) SetOrder(ctx context.Context, order *model.Order) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.orders[order.ID] = memoryEntry{
+		order:     order,
+		expiresAt: time.Now().Add(c.policies.Load().order.orderExpiration(order)),
+	}
+
+	ids, ok := c.userOrders[order.UserID]
+	if !ok {
+		ids = make(map[string]struct{})
+		c.userOrders[order.UserID] = ids
+	}
+	ids[order.ID] = struct{}{}
+	return nil
+}
+
+// DeleteOrder 从缓存中删除订单信息
+func (c *MemoryCache) DeleteOrder(ctx context.Context, orderID string, userID string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.orders, orderID)
+	if ids, ok := c.userOrders[userID]; ok {
+		delete(ids, orderID)
+		if len(ids) == 0 {
+			delete(c.userOrders, userID)
+		}
+	}
+	return nil
+}
+
+// Close 关闭缓存，进程内缓存无需释放连接
+func (c *MemoryCache) Close() error {
+	return nil
+}
+
+// InspectOrder 查看订单缓存状态
+func (c *MemoryCache) InspectOrder(ctx context.Context, orderID string) (*EntryInfo, error) {
+	c.mu.RLock()
+	entry, ok := c.orders[orderID]
+	c.mu.RUnlock()
+
+	info := &EntryInfo{OrderID: orderID, Key: orderID}
+	if ok {
+		info.Local = true
+		info.LocalExpiresAt = &entry.expiresAt
+		info.Order = entry.order
+	}
+	return info, nil
+}
+
+// EvictOrder 删除单个订单缓存
+func (c *MemoryCache) EvictOrder(ctx context.Context, orderID string) error {
+	c.mu.RLock()
+	entry, ok := c.orders[orderID]
+	c.mu.RUnlock()
+
+	if !ok {
+		return nil
+	}
+	return c.DeleteOrder(ctx, orderID, entry.order.UserID)
+}
+
+// EvictUser 删除用户的全部订单缓存
+func (c *MemoryCache) EvictUser(ctx context.Context, userID string) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ids := c.userOrders[userID]
+	for id := range ids {
+		delete(c.orders, id)
+	}
+	delete(c.userOrders, userID)
+	return len(ids), nil
+}
+
+// FlushLocal 清空进程内缓存
+func (c *MemoryCache) FlushLocal(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.orders = make(map[string]memoryEntry)
+	c.userOrders = make(map[string]map[string]struct{})
+	return nil
+}
+
+// SampleOrders 抽样读取缓存中的订单
+func (c *MemoryCache) SampleOrders(ctx context.Context, n int) ([]*model.Order, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	orders := make([]*model.Order, 0, n)
+	for _, entry := range c.orders {
+		if len(orders) >= n {
+			break
+		}
+		orders = append(orders, entry.order)
+	}
+	return orders, nil
+}
+
+// UpdatePolicies 替换过期策略，已写入的缓存保持原有的过期时间
+func (c *MemoryCache) UpdatePolicies(cfg *config.RedisConfig) {
+	c.policies.Store(newPolicies(cfg))
+}
+
+// Ping 进程内缓存始终可用
+func (c *MemoryCache) Ping(ctx context.Context) error {
+	return nil
+}
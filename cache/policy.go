@@ -0,0 +1,69 @@
+package cache
+
+import (
+	"math/rand"
+	"order_api/config"
+	"order_api/model"
+	"time"
+)
+
+// policy 已解析的缓存过期策略
+type policy struct {
+	ttl         time.Duration
+	jitter      float64
+	localTTL    time.Duration
+	terminalTTL time.Duration
+}
+
+func newPolicy(cfg *config.RedisConfig, entity string) policy {
+	p := cfg.Policy(entity)
+	return policy{
+		ttl:         time.Duration(p.TTLSeconds) * time.Second,
+		jitter:      float64(p.JitterPercent) / 100,
+		localTTL:    time.Duration(p.LocalTTLSeconds) * time.Second,
+		terminalTTL: time.Duration(p.TerminalTTLSeconds) * time.Second,
+	}
+}
+
+// policies 各缓存实体的过期策略，重新加载配置时整体替换
+type policies struct {
+	order      policy
+	userOrders policy
+}
+
+func newPolicies(cfg *config.RedisConfig) *policies {
+	return &policies{
+		order:      newPolicy(cfg, config.CacheEntityOrder),
+		userOrders: newPolicy(cfg, config.CacheEntityUserOrders),
+	}
+}
+
+// expiration 返回带随机抖动的Redis过期时间
+func (p policy) expiration() time.Duration {
+	return p.withJitter(p.ttl)
+}
+
+// orderExpiration 返回订单的过期时间，终态订单很少变化，使用更长的TTL
+func (p policy) orderExpiration(order *model.Order) time.Duration {
+	if order.Status == model.StatusDelivered || order.Status == model.StatusCancelled {
+		return p.withJitter(p.terminalTTL)
+	}
+	return p.withJitter(p.ttl)
+}
+
+// localExpiration 返回本地缓存的过期时间，不超过Redis中的剩余时间
+func (p policy) localExpiration(remote time.Duration) time.Duration {
+	if p.localTTL < remote {
+		return p.localTTL
+	}
+	return remote
+}
+
+// withJitter 在 [ttl*(1-jitter), ttl*(1+jitter)] 范围内随机取值
+func (p policy) withJitter(ttl time.Duration) time.Duration {
+	if p.jitter <= 0 {
+		return ttl
+	}
+	delta := (rand.Float64()*2 - 1) * p.jitter * float64(ttl)
+	return ttl + time.Duration(delta)
+}
@@ -1,55 +1,205 @@
 package config
 
 import (
-	"encoding/json"
+	"crypto/tls"
 	"fmt"
-	"os"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
 )
 
 // Config 系统配置结构体
 type Config struct {
-	Server   ServerConfig   `json:"server"`
-	Database DatabaseConfig `json:"database"`
-	Redis    RedisConfig    `json:"redis"`
-	Log      LogConfig      `json:"log"`
-	JWT      JWTConfig      `json:"jwt"`
+	Server    ServerConfig    `json:"server"`
+	Database  DatabaseConfig  `json:"database"`
+	Order     OrderConfig     `json:"order"`
+	Redis     RedisConfig     `json:"redis"`
+	Outbox    OutboxConfig    `json:"outbox"`
+	Retention RetentionConfig `json:"retention"`
+	Log       LogConfig       `json:"log"`
+	Health    HealthConfig    `json:"health"`
+	GRPC      GRPCConfig      `json:"grpc"`
+	JWT       JWTConfig       `json:"jwt"`
+
+	args       []string          // 加载时的命令行参数，重新加载时使用
+	files      []string          // 按加载顺序排列的配置文件路径
+	sources    map[string]Source // 每个配置项的来源
+	secretRefs map[string]string // 通过引用解析的密钥配置项及其引用
 }
 
 // ServerConfig 服务器配置
 type ServerConfig struct {
-	Port            string `json:"port"`
-	ReadTimeout     int    `json:"read_timeout"`
-	WriteTimeout    int    `json:"write_timeout"`
-	ShutdownTimeout int    `json:"shutdown_timeout"`
+	Port            string    `json:"port"`
+	ReadTimeout     int       `json:"read_timeout"`     // 读取请求的超时时间（秒），0表示不限制
+	WriteTimeout    int       `json:"write_timeout"`    // 写入响应的超时时间（秒），0表示不限制
+	ShutdownTimeout int       `json:"shutdown_timeout"` // 关闭时等待处理中的请求和后台任务的时间（秒），默认为30秒
+	ShutdownDelay   int       `json:"shutdown_delay"`   // 收到退出信号后继续处理请求的时间（秒），等待负载均衡摘除实例
+	TLS             TLSConfig `json:"tls"`
+}
+
+// 客户端证书验证方式
+const (
+	ClientAuthRequire       = "require"         // 必须提供有效的客户端证书
+	ClientAuthVerifyIfGiven = "verify_if_given" // 客户端证书可选，提供时必须有效
+)
+
+// TLSConfig HTTPS 配置，设置证书和私钥后启用，证书文件变化时自动重新加载
+type TLSConfig struct {
+	CertFile     string   `json:"cert_file"`      // 证书文件（PEM），可包含中间证书
+	KeyFile      string   `json:"key_file"`       // 私钥文件（PEM）
+	MinVersion   string   `json:"min_version"`    // 最低TLS版本：1.2/1.3，默认为1.2
+	CipherSuites []string `json:"cipher_suites"`  // 允许的密码套件名称，仅对TLS 1.2生效，为空时使用Go的默认值
+	ClientCAFile string   `json:"client_ca_file"` // 验证客户端证书的CA文件，设置后启用双向TLS，供内部服务调用
+	ClientAuth   string   `json:"client_auth"`    // 客户端证书验证方式：require/verify_if_given，默认为 require
+	DisableHTTP2 bool     `json:"disable_http2"`  // 关闭HTTP/2，只使用HTTP/1.1
 }
 
+// GRPCConfig gRPC 服务配置，设置端口后与 HTTP 服务同时启动，启用 server.tls 时使用相同的证书
+type GRPCConfig struct {
+	Port       string `json:"port"`       // 监听端口，为空表示不启动 gRPC 服务
+	Reflection bool   `json:"reflection"` // 注册反射服务，便于 grpcurl 等工具调试
+}
+
+// Enabled 是否启动 gRPC 服务
+func (c *GRPCConfig) Enabled() bool {
+	return c.Port != ""
+}
+
+// 数据库驱动
+const (
+	DriverMySQL    = "mysql"
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+)
+
 // DatabaseConfig 数据库配置
 type DatabaseConfig struct {
+	Driver       string `json:"driver"` // 数据库驱动：mysql/postgres/sqlite，默认为 mysql
 	Host         string `json:"host"`
 	Port         string `json:"port"`
 	User         string `json:"user"`
-	Password     string `json:"password"`
+	Password     Secret `json:"password"`
 	DBName       string `json:"dbname"`
-	Charset      string `json:"charset"`
+	Charset      string `json:"charset"`  // 仅 MySQL 使用
+	SSLMode      string `json:"ssl_mode"` // 仅 PostgreSQL 使用，默认为 disable
 	MaxIdleConns int    `json:"max_idle_conns"`
 	MaxOpenConns int    `json:"max_open_conns"`
+	AutoMigrate  bool   `json:"auto_migrate"` // 启动时自动执行未应用的迁移，生产环境建议关闭并使用 migrate 命令
+
+	ConnMaxLifetime int `json:"conn_max_lifetime"`  // 连接最大存活时间（秒），0表示不限制
+	ConnMaxIdleTime int `json:"conn_max_idle_time"` // 连接最大空闲时间（秒），0表示不限制
+	ConnectTimeout  int `json:"connect_timeout"`    // 建立连接超时时间（秒）
+	ConnectRetries  int `json:"connect_retries"`    // 启动时连接失败的重试次数
+	RetryBackoff    int `json:"retry_backoff"`      // 首次重试等待时间（秒），之后指数递增
+
+	SlowQueryMs        int  `json:"slow_query_ms"`        // 慢查询阈值（毫秒），默认为200，负数表示不记录慢查询
+	LogQueries         bool `json:"log_queries"`          // 记录全部SQL语句，用于调试
+	LogQueryParams     bool `json:"log_query_params"`     // 日志中记录SQL参数值，默认以占位符代替，避免泄露用户数据
+	ExplainSlowQueries bool `json:"explain_slow_queries"` // 调试模式：对慢查询执行 EXPLAIN 并记录执行计划
+
+	Replicas             []ReplicaConfig `json:"replicas"`               // 只读副本，查询在副本间轮询
+	ReplicaStickySeconds int             `json:"replica_sticky_seconds"` // 用户写入后在该时间内读主库，规避复制延迟，0表示关闭；写入记录只保存在处理写入的实例内存中，多实例部署时落到其他实例的查询仍可能读到延迟的副本
+
+	Shards []ShardConfig `json:"shards"` // 附加分片，上面配置的数据库为0号分片，订单按 user_id 哈希分布到各分片
+}
+
+// MaxShards 分片数量上限，分片序号编码在订单ID的首字节中
+const MaxShards = 256
+
+// ShardConfig 分片配置，未配置的连接字段沿用0号分片的配置
+type ShardConfig struct {
+	Host     string          `json:"host"`
+	Port     string          `json:"port"`
+	User     string          `json:"user"`
+	Password Secret          `json:"password"`
+	DBName   string          `json:"dbname"`
+	Replicas []ReplicaConfig `json:"replicas"` // 该分片的只读副本
+}
+
+// ReplicaConfig 只读副本配置，未配置的字段沿用主库配置
+type ReplicaConfig struct {
+	Host     string `json:"host"`
+	Port     string `json:"port"`
+	User     string `json:"user"`
+	Password Secret `json:"password"`
+	DBName   string `json:"dbname"`
 }
 
+// 缓存后端模式
+const (
+	RedisModeStandalone = "standalone" // 单节点Redis
+	RedisModeSentinel   = "sentinel"   // Redis Sentinel 主从故障转移
+	RedisModeCluster    = "cluster"    // Redis Cluster
+	RedisModeMemory     = "memory"     // 纯进程内缓存，用于本地开发和测试
+)
+
 // RedisConfig Redis配置
 type RedisConfig struct {
-	Host         string `json:"host"`
-	Port         string `json:"port"`
-	Password     string `json:"password"`
-	DB           int    `json:"db"`
-	MaxRetries   int    `json:"max_retries"`
-	PoolSize     int    `json:"pool_size"`
-	MaxIdleConns int    `json:"max_idle_conns"`
-	ExpireHours  int    `json:"expire_hours"`
+	Mode              string   `json:"mode"` // 缓存后端模式，默认为 standalone
+	Host              string   `json:"host"`
+	Port              string   `json:"port"`
+	Addrs             []string `json:"addrs"`             // Sentinel/Cluster 节点地址
+	MasterName        string   `json:"master_name"`       // Sentinel 主节点名称
+	SentinelPassword  Secret   `json:"sentinel_password"` // Sentinel 认证密码
+	Password          Secret   `json:"password"`
+	DB                int      `json:"db"`
+	MaxRetries        int      `json:"max_retries"`
+	PoolSize          int      `json:"pool_size"`
+	MaxIdleConns      int      `json:"max_idle_conns"`
+	ExpireHours       int      `json:"expire_hours"`
+	KeyPrefix         string   `json:"key_prefix"`         // 缓存键命名空间前缀
+	SchemaVersion     int      `json:"schema_version"`     // 缓存数据结构版本，变更后旧缓存自动失效
+	Codec             string   `json:"codec"`              // 缓存编码：json/msgpack
+	Compression       string   `json:"compression"`        // 缓存压缩：none/snappy/zstd
+	CompressThreshold int      `json:"compress_threshold"` // 超过该字节数才压缩
+
+	Policies map[string]CachePolicy `json:"policies"` // 按实体划分的缓存策略，如 order、user_orders
+	Warmup   CacheWarmupConfig      `json:"warmup"`   // 启动预热配置
+
+	WriteStrategy string                 `json:"write_strategy"` // 写入策略：write_through/invalidate
+	Consistency   CacheConsistencyConfig `json:"consistency"`    // 缓存一致性巡检配置
+}
+
+// 缓存写入策略
+const (
+	WriteStrategyWriteThrough = "write_through" // 事务提交后从数据库重新加载并写入缓存
+	WriteStrategyInvalidate   = "invalidate"    // 事务提交后删除缓存，下次读取时再加载
+)
+
+// CacheConsistencyConfig 缓存一致性巡检配置
+type CacheConsistencyConfig struct {
+	Enabled         bool `json:"enabled"`
+	IntervalSeconds int  `json:"interval_seconds"` // 巡检间隔
+	SampleSize      int  `json:"sample_size"`      // 每次抽样的订单数量
+	Repair          bool `json:"repair"`           // 发现不一致时是否删除缓存
+}
+
+// CacheWarmupConfig 缓存预热配置
+type CacheWarmupConfig struct {
+	Enabled     bool `json:"enabled"`
+	Hours       int  `json:"hours"`       // 预热最近N小时内更新过的订单
+	Limit       int  `json:"limit"`       // 最多预热的订单数量
+	Concurrency int  `json:"concurrency"` // 并发写入缓存的协程数
+}
+
+// 缓存实体名称
+const (
+	CacheEntityOrder      = "order"       // 订单详情
+	CacheEntityUserOrders = "user_orders" // 用户订单ID集合
+)
+
+// CachePolicy 单个缓存实体的过期策略
+type CachePolicy struct {
+	TTLSeconds         int `json:"ttl_seconds"`          // Redis过期时间，为0时使用 expire_hours
+	JitterPercent      int `json:"jitter_percent"`       // 过期时间随机抖动百分比，避免集中过期
+	LocalTTLSeconds    int `json:"local_ttl_seconds"`    // 本地缓存过期时间，为0时与TTL一致
+	TerminalTTLSeconds int `json:"terminal_ttl_seconds"` // 已送达/已取消订单的过期时间，为0时与TTL一致
 }
 
 // LogConfig 日志配置
 type LogConfig struct {
-	Level      string `json:"level"`
+	Level      string `json:"level"` // 结构化日志级别：debug/info/warn/error，默认为 info
 	Filename   string `json:"filename"`
 	MaxSize    int    `json:"max_size"`
 	MaxBackups int    `json:"max_backups"`
@@ -57,80 +207,507 @@ type LogConfig struct {
 	Compress   bool   `json:"compress"`
 }
 
+// 发件箱事件发布方式
+const (
+	PublisherLog     = "log"     // 写入日志，用于开发调试
+	PublisherWebhook = "webhook" // 通过HTTP回调推送
+	PublisherLocal   = "local"   // 进程内主题订阅
+)
+
+// OutboxConfig 事务性发件箱配置
+type OutboxConfig struct {
+	Enabled             bool          `json:"enabled"`
+	Publisher           string        `json:"publisher"`             // 发布方式：log/webhook/local，默认为 log
+	PollIntervalSeconds int           `json:"poll_interval_seconds"` // 投递器轮询间隔
+	BatchSize           int           `json:"batch_size"`            // 每轮最多投递的事件数
+	MaxAttempts         int           `json:"max_attempts"`          // 超过该次数仍失败的事件进入死信
+	RetryBackoff        int           `json:"retry_backoff"`         // 首次重试等待时间（秒），之后指数递增
+	LeaseSeconds        int           `json:"lease_seconds"`         // 投递租约，实例崩溃后租约到期的事件可被重新投递
+	RetentionHours      int           `json:"retention_hours"`       // 已投递事件的保留时间，0表示不清理
+	Webhook             WebhookConfig `json:"webhook"`
+}
+
+// WebhookConfig HTTP回调配置
+type WebhookConfig struct {
+	URL            string `json:"url"`
+	Secret         Secret `json:"secret"` // 非空时对请求体做 HMAC-SHA256 签名
+	TimeoutSeconds int    `json:"timeout_seconds"`
+}
+
+// 软删除订单到期后的处理方式
+const (
+	RetentionActionPurge   = "purge"   // 物理删除
+	RetentionActionArchive = "archive" // 归档后删除
+)
+
+// 归档目标
+const (
+	ArchiveTargetTable = "table" // 写入 orders_archive 表
+	ArchiveTargetJSONL = "jsonl" // 导出为 gzip 压缩的 JSONL 文件
+)
+
+// RetentionConfig 订单保留与归档配置
+type RetentionConfig struct {
+	Enabled              bool   `json:"enabled"`
+	IntervalMinutes      int    `json:"interval_minutes"`       // 执行间隔，默认为60分钟
+	DeletedRetentionDays int    `json:"deleted_retention_days"` // 软删除订单的保留天数，0表示不清理
+	DeletedAction        string `json:"deleted_action"`         // 到期软删除订单的处理方式：purge/archive，默认为 purge
+	ArchiveAfterYears    int    `json:"archive_after_years"`    // 已完成订单超过该年限后归档，0表示不归档
+	ArchiveTarget        string `json:"archive_target"`         // 归档目标：table/jsonl，默认为 table
+	ArchiveDir           string `json:"archive_dir"`            // JSONL 归档文件目录
+	BatchSize            int    `json:"batch_size"`             // 每批处理的订单数
+}
+
+// OrderConfig 订单配置
+type OrderConfig struct {
+	NumberPrefix string `json:"number_prefix"` // 订单号前缀，只能包含字母和数字，默认为 ORD
+}
+
+// maxNumberPrefixLen 订单号前缀的最大长度，保证订单号不超过数据库列宽
+const maxNumberPrefixLen = 8
+
+// HealthConfig 就绪检查配置
+type HealthConfig struct {
+	TimeoutMs           int `json:"timeout_ms"`             // 单项检查的超时时间（毫秒），默认为2000
+	CacheMs             int `json:"cache_ms"`               // 检查结果的缓存时间（毫秒），默认为1000，避免探针频繁访问依赖
+	OutboxMaxLagSeconds int `json:"outbox_max_lag_seconds"` // 待投递事件积压超过该时间时视为未就绪，默认为300，负数表示不检查
+}
+
 // JWTConfig JWT配置
 type JWTConfig struct {
-	SecretKey          string `json:"secret_key"`
+	SecretKey          Secret `json:"secret_key"`
 	TokenExpiryHours   int    `json:"token_expiry_hours"`
 	RefreshExpiryHours int    `json:"refresh_expiry_hours"`
 }
 
-// NewConfig 创建新的配置实例
-func NewConfig() *Config {
-	config := &Config{}
-	if err := config.Load(); err != nil {
-		panic(fmt.Sprintf("加载配置文件失败: %v", err))
-	}
-	return config
+// ValidationErrors 配置验证发现的全部问题
+type ValidationErrors []string
+
+func (e ValidationErrors) Error() string {
+	return strings.Join(e, "; ")
 }
 
-// Load 加载配置文件
-func (c *Config) Load() error {
-	// 读取配置文件
-	data, err := os.ReadFile("config/config.json")
-	if err != nil {
-		return fmt.Errorf("读取配置文件失败: %w", err)
-	}
+func (e *ValidationErrors) addf(format string, args ...interface{}) {
+	*e = append(*e, fmt.Sprintf(format, args...))
+}
+
+// validate 验证配置，返回发现的全部问题
+func (c *Config) validate() error {
+	var problems ValidationErrors
 
-	// 解析JSON配置
-	if err := json.Unmarshal(data, c); err != nil {
-		return fmt.Errorf("解析配置文件失败: %w", err)
+	// 验证服务器配置
+	if c.Server.Port == "" {
+		problems.addf("服务器端口不能为空")
 	}
 
-	// 验证配置
-	if err := c.validate(); err != nil {
-		return fmt.Errorf("配置验证失败: %w", err)
+	if c.Server.ReadTimeout < 0 || c.Server.WriteTimeout < 0 || c.Server.ShutdownDelay < 0 {
+		problems.addf("服务器超时时间不能为负数")
 	}
 
-	return nil
-}
+	if c.GRPC.Enabled() && c.GRPC.Port == c.Server.Port {
+		problems.addf("gRPC端口不能与HTTP端口相同")
+	}
 
-// validate 验证配置
-func (c *Config) validate() error {
-	// 验证服务器配置
-	if c.Server.Port == "" {
-		return fmt.Errorf("服务器端口不能为空")
+	// 验证TLS配置
+	if tlsCfg := c.Server.TLS; tlsCfg.CertFile != "" || tlsCfg.KeyFile != "" || tlsCfg.ClientCAFile != "" {
+		if tlsCfg.CertFile == "" || tlsCfg.KeyFile == "" {
+			problems.addf("TLS配置不完整，cert_file 和 key_file 需同时设置")
+		}
+		if _, ok := tlsVersions[tlsCfg.MinVersion]; !ok {
+			problems.addf("不支持的TLS版本: %s", tlsCfg.MinVersion)
+		}
+		if _, err := tlsCfg.GetCipherSuites(); err != nil {
+			problems.addf("%v", err)
+		}
+		switch tlsCfg.ClientAuth {
+		case "", ClientAuthRequire, ClientAuthVerifyIfGiven:
+		default:
+			problems.addf("不支持的客户端证书验证方式: %s", tlsCfg.ClientAuth)
+		}
+		if tlsCfg.ClientAuth != "" && tlsCfg.ClientCAFile == "" {
+			problems.addf("设置 client_auth 时需要同时设置 client_ca_file")
+		}
 	}
 
 	// 验证数据库配置
-	if c.Database.Host == "" || c.Database.Port == "" ||
-		c.Database.User == "" || c.Database.DBName == "" {
-		return fmt.Errorf("数据库配置不完整")
+	switch c.Database.Driver {
+	case "", DriverMySQL, DriverPostgres:
+		var missing []string
+		for name, value := range map[string]string{
+			"host": c.Database.Host, "port": c.Database.Port, "user": c.Database.User, "dbname": c.Database.DBName,
+		} {
+			if value == "" {
+				missing = append(missing, "database."+name)
+			}
+		}
+		if len(missing) > 0 {
+			sort.Strings(missing)
+			problems.addf("数据库配置不完整，缺少 %s", strings.Join(missing, ", "))
+		}
+	case DriverSQLite:
+		// SQLite 的 dbname 为数据库文件路径，:memory: 表示内存数据库
+		if c.Database.DBName == "" {
+			problems.addf("数据库配置不完整，缺少 database.dbname")
+		}
+	default:
+		problems.addf("不支持的数据库驱动: %s", c.Database.Driver)
+	}
+	for i, replica := range c.Database.Replicas {
+		// 副本至少需要指定自己的地址（SQLite 为数据库文件路径）
+		if replica.Host == "" && replica.DBName == "" {
+			problems.addf("只读副本%d配置不完整", i+1)
+		}
+	}
+	if c.Database.ReplicaStickySeconds < 0 {
+		problems.addf("replica_sticky_seconds 不能为负数")
+	}
+	if c.Database.ShardCount() > MaxShards {
+		problems.addf("分片数量不能超过%d", MaxShards)
+	}
+	for i, shard := range c.Database.Shards {
+		if shard.Host == "" && shard.DBName == "" {
+			problems.addf("分片%d配置不完整", i+1)
+		}
+		for j, replica := range shard.Replicas {
+			if replica.Host == "" && replica.DBName == "" {
+				problems.addf("分片%d的只读副本%d配置不完整", i+1, j+1)
+			}
+		}
 	}
 
 	// 验证Redis配置
-	if c.Redis.Host == "" || c.Redis.Port == "" {
-		return fmt.Errorf("Redis配置不完整")
+	switch c.Redis.Mode {
+	case "", RedisModeStandalone:
+		if c.Redis.Host == "" || c.Redis.Port == "" {
+			problems.addf("Redis配置不完整")
+		}
+	case RedisModeSentinel:
+		if c.Redis.MasterName == "" || len(c.Redis.Addrs) == 0 {
+			problems.addf("Redis Sentinel配置不完整")
+		}
+	case RedisModeCluster:
+		if len(c.Redis.Addrs) == 0 {
+			problems.addf("Redis Cluster配置不完整")
+		}
+	case RedisModeMemory:
+	default:
+		problems.addf("不支持的Redis模式: %s", c.Redis.Mode)
+	}
+	switch c.Redis.WriteStrategy {
+	case "", WriteStrategyWriteThrough, WriteStrategyInvalidate:
+	default:
+		problems.addf("不支持的缓存写入策略: %s", c.Redis.WriteStrategy)
+	}
+	for entity, policy := range c.Redis.Policies {
+		if policy.JitterPercent < 0 || policy.JitterPercent >= 100 {
+			problems.addf("缓存策略%s的抖动百分比必须在0-99之间", entity)
+		}
+	}
+
+	// 验证发件箱配置
+	switch c.Outbox.Publisher {
+	case "", PublisherLog, PublisherLocal:
+	case PublisherWebhook:
+		if c.Outbox.Webhook.URL == "" {
+			problems.addf("发件箱Webhook地址不能为空")
+		}
+	default:
+		problems.addf("不支持的事件发布方式: %s", c.Outbox.Publisher)
+	}
+
+	// 验证订单配置
+	if prefix := c.Order.NumberPrefix; prefix != "" {
+		if len(prefix) > maxNumberPrefixLen || strings.IndexFunc(prefix, func(r rune) bool {
+			return !(r >= 'A' && r <= 'Z' || r >= 'a' && r <= 'z' || r >= '0' && r <= '9')
+		}) >= 0 {
+			problems.addf("订单号前缀只能包含字母和数字，且不超过%d个字符", maxNumberPrefixLen)
+		}
+	}
+
+	// 验证保留策略配置
+	switch c.Retention.DeletedAction {
+	case "", RetentionActionPurge, RetentionActionArchive:
+	default:
+		problems.addf("不支持的软删除订单处理方式: %s", c.Retention.DeletedAction)
+	}
+	switch c.Retention.ArchiveTarget {
+	case "", ArchiveTargetTable:
+	case ArchiveTargetJSONL:
+		if c.Retention.ArchiveDir == "" {
+			problems.addf("归档目录不能为空")
+		}
+	default:
+		problems.addf("不支持的归档目标: %s", c.Retention.ArchiveTarget)
+	}
+
+	// 验证日志配置
+	if _, ok := logLevels[strings.ToLower(c.Log.Level)]; !ok {
+		problems.addf("不支持的日志级别: %s", c.Log.Level)
 	}
 
 	// 验证JWT配置
 	if c.JWT.SecretKey == "" || c.JWT.TokenExpiryHours <= 0 {
-		return fmt.Errorf("JWT配置不完整")
+		problems.addf("JWT配置不完整")
 	}
 
+	if len(problems) > 0 {
+		return problems
+	}
 	return nil
 }
 
-// GetDSN 获取数据库连接字符串
+// GetShutdownTimeout 获取优雅关闭的超时时间，默认为30秒
+func (c *ServerConfig) GetShutdownTimeout() time.Duration {
+	if c.ShutdownTimeout <= 0 {
+		return 30 * time.Second
+	}
+	return time.Duration(c.ShutdownTimeout) * time.Second
+}
+
+// tlsVersions TLS版本名称
+var tlsVersions = map[string]uint16{
+	"":    tls.VersionTLS12,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// Enabled 是否启用HTTPS
+func (c *TLSConfig) Enabled() bool {
+	return c.CertFile != "" && c.KeyFile != ""
+}
+
+// GetMinVersion 获取最低TLS版本，默认为TLS 1.2
+func (c *TLSConfig) GetMinVersion() uint16 {
+	return tlsVersions[c.MinVersion]
+}
+
+// GetCipherSuites 将密码套件名称转换为ID，只接受Go认为安全的套件
+func (c *TLSConfig) GetCipherSuites() ([]uint16, error) {
+	if len(c.CipherSuites) == 0 {
+		return nil, nil
+	}
+	known := make(map[string]uint16)
+	for _, suite := range tls.CipherSuites() {
+		known[suite.Name] = suite.ID
+	}
+	ids := make([]uint16, 0, len(c.CipherSuites))
+	for _, name := range c.CipherSuites {
+		id, ok := known[name]
+		if !ok {
+			return nil, fmt.Errorf("不支持的密码套件: %s", name)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// GetClientAuth 获取客户端证书验证方式，未设置 client_ca_file 时不验证客户端证书
+func (c *TLSConfig) GetClientAuth() tls.ClientAuthType {
+	switch {
+	case c.ClientCAFile == "":
+		return tls.NoClientCert
+	case c.ClientAuth == ClientAuthVerifyIfGiven:
+		return tls.VerifyClientCertIfGiven
+	default:
+		return tls.RequireAndVerifyClientCert
+	}
+}
+
+// GetTimeout 获取单项检查的超时时间，默认为2秒
+func (c *HealthConfig) GetTimeout() time.Duration {
+	if c.TimeoutMs <= 0 {
+		return 2 * time.Second
+	}
+	return time.Duration(c.TimeoutMs) * time.Millisecond
+}
+
+// GetCacheTTL 获取检查结果的缓存时间，默认为1秒
+func (c *HealthConfig) GetCacheTTL() time.Duration {
+	if c.CacheMs <= 0 {
+		return time.Second
+	}
+	return time.Duration(c.CacheMs) * time.Millisecond
+}
+
+// GetOutboxMaxLag 获取允许的事件积压时间，默认为5分钟，返回0表示不检查
+func (c *HealthConfig) GetOutboxMaxLag() time.Duration {
+	switch {
+	case c.OutboxMaxLagSeconds < 0:
+		return 0
+	case c.OutboxMaxLagSeconds == 0:
+		return 5 * time.Minute
+	default:
+		return time.Duration(c.OutboxMaxLagSeconds) * time.Second
+	}
+}
+
+// GetDriver 获取数据库驱动名称
+func (c *DatabaseConfig) GetDriver() string {
+	if c.Driver == "" {
+		return DriverMySQL
+	}
+	return c.Driver
+}
+
+// GetDSN 获取对应驱动的数据库连接字符串
 func (c *DatabaseConfig) GetDSN() string {
-	return fmt.Sprintf(
-		"%s:%s@tcp(%s:%s)/%s?charset=%s&parseTime=True&loc=Local",
-		c.User,
-		c.Password,
-		c.Host,
-		c.Port,
-		c.DBName,
-		c.Charset,
-	)
+	switch c.GetDriver() {
+	case DriverPostgres:
+		sslMode := c.SSLMode
+		if sslMode == "" {
+			sslMode = "disable"
+		}
+		return fmt.Sprintf(
+			"host=%s port=%s user=%s password=%s dbname=%s sslmode=%s connect_timeout=%d",
+			c.Host,
+			c.Port,
+			c.User,
+			string(c.Password),
+			c.DBName,
+			sslMode,
+			c.GetConnectTimeout(),
+		)
+	case DriverSQLite:
+		// 内存数据库使用共享缓存，保证连接池中的连接访问同一个库
+		path := c.DBName
+		if path == ":memory:" {
+			path = "file::memory:?cache=shared"
+		}
+		separator := "?"
+		if strings.Contains(path, "?") {
+			separator = "&"
+		}
+		return path + separator + "_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)"
+	default:
+		return fmt.Sprintf(
+			"%s:%s@tcp(%s:%s)/%s?charset=%s&parseTime=True&loc=Local&timeout=%ds",
+			c.User,
+			string(c.Password),
+			c.Host,
+			c.Port,
+			c.DBName,
+			c.Charset,
+			c.GetConnectTimeout(),
+		)
+	}
+}
+
+// ReplicaConfig 获取第 i 个只读副本的完整数据库配置
+func (c *DatabaseConfig) ReplicaConfig(i int) DatabaseConfig {
+	replica := *c
+	replica.Replicas = nil
+	replica.Shards = nil
+	r := c.Replicas[i]
+	if r.Host != "" {
+		replica.Host = r.Host
+	}
+	if r.Port != "" {
+		replica.Port = r.Port
+	}
+	if r.User != "" {
+		replica.User = r.User
+	}
+	if r.Password != "" {
+		replica.Password = r.Password
+	}
+	if r.DBName != "" {
+		replica.DBName = r.DBName
+	}
+	return replica
+}
+
+// ShardCount 获取分片数量，包含0号分片
+func (c *DatabaseConfig) ShardCount() int {
+	return len(c.Shards) + 1
+}
+
+// ShardConfig 获取第 i 个分片的完整数据库配置，0 表示0号分片
+func (c *DatabaseConfig) ShardConfig(i int) DatabaseConfig {
+	shard := *c
+	shard.Shards = nil
+	if i == 0 {
+		return shard
+	}
+
+	s := c.Shards[i-1]
+	if s.Host != "" {
+		shard.Host = s.Host
+	}
+	if s.Port != "" {
+		shard.Port = s.Port
+	}
+	if s.User != "" {
+		shard.User = s.User
+	}
+	if s.Password != "" {
+		shard.Password = s.Password
+	}
+	if s.DBName != "" {
+		shard.DBName = s.DBName
+	}
+	shard.Replicas = s.Replicas
+	return shard
+}
+
+// GetConnectTimeout 获取建立连接的超时时间（秒），默认为10秒
+func (c *DatabaseConfig) GetConnectTimeout() int {
+	if c.ConnectTimeout <= 0 {
+		return 10
+	}
+	return c.ConnectTimeout
+}
+
+// GetSlowQueryMs 获取慢查询阈值（毫秒），默认为200，返回0表示不记录慢查询
+func (c *DatabaseConfig) GetSlowQueryMs() int {
+	switch {
+	case c.SlowQueryMs < 0:
+		return 0
+	case c.SlowQueryMs == 0:
+		return 200
+	default:
+		return c.SlowQueryMs
+	}
+}
+
+// logLevels 日志级别名称
+var logLevels = map[string]slog.Level{
+	"":        slog.LevelInfo,
+	"debug":   slog.LevelDebug,
+	"info":    slog.LevelInfo,
+	"warn":    slog.LevelWarn,
+	"warning": slog.LevelWarn,
+	"error":   slog.LevelError,
+}
+
+// SlogLevel 获取结构化日志级别，默认为 info
+func (c *LogConfig) SlogLevel() slog.Level {
+	return logLevels[strings.ToLower(c.Level)]
+}
+
+// GetNumberPrefix 获取订单号前缀，默认为 ORD
+func (c *OrderConfig) GetNumberPrefix() string {
+	if c.NumberPrefix == "" {
+		return "ORD"
+	}
+	return c.NumberPrefix
+}
+
+// Policy 获取指定实体的缓存策略，未配置的字段使用默认值
+func (c *RedisConfig) Policy(entity string) CachePolicy {
+	policy := c.Policies[entity]
+	if policy.TTLSeconds <= 0 {
+		policy.TTLSeconds = 30 * 60
+		if c.ExpireHours > 0 {
+			policy.TTLSeconds = c.ExpireHours * 3600
+		}
+	}
+	if policy.LocalTTLSeconds <= 0 {
+		policy.LocalTTLSeconds = policy.TTLSeconds
+	}
+	if policy.TerminalTTLSeconds <= 0 {
+		policy.TerminalTTLSeconds = policy.TTLSeconds
+	}
+	return policy
 }
 
 // GetRedisAddr 获取Redis连接地址
@@ -0,0 +1,556 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// Source 配置值的来源
+type Source string
+
+const (
+	SourceDefault Source = "default" // 内置默认值
+	SourceFile    Source = "file"    // 配置文件
+	SourceProfile Source = "profile" // APP_ENV 指定的环境配置文件
+	SourceEnv     Source = "env"     // 环境变量
+	SourceFlag    Source = "flag"    // 命令行参数
+)
+
+const (
+	// EnvPrefix 配置项对应的环境变量前缀，如 database.password 对应 ORDER_API_DATABASE_PASSWORD
+	EnvPrefix = "ORDER_API_"
+	// EnvConfigFile 指定配置文件路径的环境变量，优先级低于 --config 参数
+	EnvConfigFile = "ORDER_API_CONFIG"
+	// EnvProfile 选择环境配置文件的环境变量，如 APP_ENV=prod 时在 config.yaml 之上叠加 config.prod.yaml
+	EnvProfile = "APP_ENV"
+)
+
+// defaultConfigStem 未指定配置文件时的默认路径（不含扩展名），支持 .json/.yaml/.yml/.toml
+const defaultConfigStem = "config/config"
+
+// Setting 一项生效的配置及其来源
+type Setting struct {
+	Key    string
+	Value  string
+	Source Source
+}
+
+// field 配置项在 Config 中的位置，嵌套结构体展开为以点分隔的键，列表和映射作为一个整体
+type field struct {
+	key   string
+	index []int
+}
+
+// defaults 内置默认值，配置文件、环境变量和命令行参数依次覆盖
+func defaults() *Config {
+	return &Config{
+		Server: ServerConfig{
+			Port:            "8080",
+			ReadTimeout:     60,
+			WriteTimeout:    60,
+			ShutdownTimeout: 30,
+		},
+		Database: DatabaseConfig{
+			Driver:       DriverMySQL,
+			Host:         "localhost",
+			Port:         "3306",
+			Charset:      "utf8mb4",
+			MaxIdleConns: 10,
+			MaxOpenConns: 100,
+		},
+		Redis: RedisConfig{
+			Mode: RedisModeStandalone,
+			Host: "localhost",
+			Port: "6379",
+		},
+		JWT: JWTConfig{
+			TokenExpiryHours: 24,
+		},
+	}
+}
+
+// Load 按 默认值、配置文件、环境配置文件、环境变量、命令行参数 的顺序分层加载配置，后面的来源覆盖前面的
+// args 为不含程序名的命令行参数，配置参数需写在子命令之前，返回解析后剩余的参数
+func Load(args []string) (*Config, []string, error) {
+	c := defaults()
+	c.args = args
+	keys := configFields(reflect.TypeOf(*c), "", nil)
+	c.sources = make(map[string]Source, len(keys))
+	for _, f := range keys {
+		c.sources[f.key] = SourceDefault
+	}
+
+	// 先解析命令行参数以获得配置文件路径，参数值在环境变量之后应用
+	type override struct {
+		field field
+		value string
+	}
+	var overrides []override
+	flags := flag.NewFlagSet(filepath.Base(os.Args[0]), flag.ContinueOnError)
+	configFile := flags.String("config", "", "配置文件路径，也可以通过环境变量 "+EnvConfigFile+" 指定")
+	for _, f := range keys {
+		f := f
+		flags.Var(&flagValue{
+			kind: reflect.ValueOf(c).Elem().FieldByIndex(f.index).Kind(),
+			set: func(value string) error {
+				overrides = append(overrides, override{field: f, value: value})
+				return nil
+			},
+		}, f.key, "覆盖配置项 "+f.key)
+	}
+	if err := flags.Parse(args); err != nil {
+		return nil, nil, err
+	}
+
+	if err := c.loadFiles(*configFile, keys); err != nil {
+		return nil, nil, err
+	}
+
+	known := map[string]bool{EnvConfigFile: true}
+	for _, f := range keys {
+		name := envName(f.key)
+		known[name] = true
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			continue
+		}
+		if err := c.set(f, value); err != nil {
+			return nil, nil, fmt.Errorf("环境变量%s无效: %w", name, err)
+		}
+		c.sources[f.key] = SourceEnv
+	}
+	for _, env := range os.Environ() {
+		name, _, _ := strings.Cut(env, "=")
+		if strings.HasPrefix(name, EnvPrefix) && !known[name] {
+			log.Printf("忽略未知的配置环境变量: %s", name)
+		}
+	}
+
+	for _, o := range overrides {
+		if err := c.set(o.field, o.value); err != nil {
+			return nil, nil, fmt.Errorf("命令行参数--%s无效: %w", o.field.key, err)
+		}
+		c.sources[o.field.key] = SourceFlag
+	}
+
+	if err := c.resolveSecrets(context.Background()); err != nil {
+		return nil, nil, err
+	}
+	if err := c.validate(); err != nil {
+		return nil, nil, fmt.Errorf("配置验证失败: %w", err)
+	}
+	return c, flags.Args(), nil
+}
+
+// loadFiles 读取配置文件和 APP_ENV 指定的环境配置文件，环境配置逐项覆盖基础配置
+// 未显式指定配置文件且默认位置不存在时只使用默认值
+func (c *Config) loadFiles(path string, keys []field) error {
+	if path == "" {
+		path = os.Getenv(EnvConfigFile)
+	}
+	if path == "" {
+		found, err := findConfig(defaultStems("")...)
+		if err != nil {
+			return err
+		}
+		path = found
+	}
+
+	type layer struct {
+		path   string
+		source Source
+	}
+	var layers []layer
+	if path != "" {
+		layers = append(layers, layer{path: path, source: SourceFile})
+	}
+	if profile := os.Getenv(EnvProfile); profile != "" {
+		// 环境配置文件与基础配置文件同目录，如 config.yaml 对应 config.prod.yaml，扩展名可以不同
+		stems := defaultStems("." + profile)
+		if path != "" {
+			stems = []string{strings.TrimSuffix(path, filepath.Ext(path)) + "." + profile}
+		}
+		found, err := findConfig(stems...)
+		if err != nil {
+			return err
+		}
+		if found == "" {
+			return fmt.Errorf("未找到%s=%s对应的配置文件: %s.*", EnvProfile, profile, stems[0])
+		}
+		layers = append(layers, layer{path: found, source: SourceProfile})
+	}
+	if len(layers) == 0 {
+		return nil
+	}
+
+	merged := map[string]interface{}{}
+	for _, l := range layers {
+		raw, err := decodeFile(l.path)
+		if err != nil {
+			return err
+		}
+		for _, f := range keys {
+			if present(raw, f.key) {
+				c.sources[f.key] = l.source
+			}
+		}
+		merge(merged, raw)
+		c.files = append(c.files, l.path)
+	}
+
+	data, err := json.Marshal(coerce(merged, reflect.TypeOf(*c)))
+	if err != nil {
+		return fmt.Errorf("解析配置文件失败: %w", err)
+	}
+	if err := json.Unmarshal(data, c); err != nil {
+		return fmt.Errorf("解析配置文件失败: %w", err)
+	}
+	return nil
+}
+
+// configExts 支持的配置文件扩展名
+var configExts = []string{".json", ".yaml", ".yml", ".toml"}
+
+// defaultStems 返回默认配置文件的候选路径（不含扩展名），先工作目录，再可执行文件所在目录
+func defaultStems(suffix string) []string {
+	stems := []string{defaultConfigStem + suffix}
+	if exe, err := os.Executable(); err == nil {
+		stems = append(stems, filepath.Join(filepath.Dir(exe), defaultConfigStem+suffix))
+	}
+	return stems
+}
+
+// findConfig 依次在候选路径上查找任一支持格式的配置文件，同一位置存在多种格式时报错
+func findConfig(stems ...string) (string, error) {
+	for _, stem := range stems {
+		var found []string
+		for _, ext := range configExts {
+			if _, err := os.Stat(stem + ext); err == nil {
+				found = append(found, stem+ext)
+			}
+		}
+		switch len(found) {
+		case 0:
+		case 1:
+			return found[0], nil
+		default:
+			return "", fmt.Errorf("存在多个配置文件，无法确定使用哪一个: %s", strings.Join(found, ", "))
+		}
+	}
+	return "", nil
+}
+
+// decodeFile 按扩展名解析 JSON、YAML 或 TOML 配置文件
+func decodeFile(path string) (map[string]interface{}, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取配置文件失败: %w", err)
+	}
+
+	raw := map[string]interface{}{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		err = json.Unmarshal(data, &raw)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &raw)
+	case ".toml":
+		err = toml.Unmarshal(data, &raw)
+	default:
+		return nil, fmt.Errorf("不支持的配置文件格式: %s", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("解析配置文件%s失败: %w", path, err)
+	}
+	return raw, nil
+}
+
+// merge 将 src 逐层合并到 dst，两边都是对象时递归合并，否则以 src 为准，列表整体替换
+func merge(dst, src map[string]interface{}) {
+	for key, value := range src {
+		if from, ok := value.(map[string]interface{}); ok {
+			if to, ok := dst[key].(map[string]interface{}); ok {
+				merge(to, from)
+				continue
+			}
+		}
+		dst[key] = value
+	}
+}
+
+// coerce 按配置字段的类型调整解析出的值，YAML/TOML 中的 port: 3306 会被解析为数字，而端口字段为字符串
+func coerce(value interface{}, t reflect.Type) interface{} {
+	switch t.Kind() {
+	case reflect.Struct:
+		if m, ok := value.(map[string]interface{}); ok {
+			for i := 0; i < t.NumField(); i++ {
+				name := jsonName(t.Field(i))
+				if v, ok := m[name]; ok && name != "" {
+					m[name] = coerce(v, t.Field(i).Type)
+				}
+			}
+		}
+	case reflect.Slice:
+		if items, ok := value.([]interface{}); ok {
+			for i := range items {
+				items[i] = coerce(items[i], t.Elem())
+			}
+		}
+	case reflect.Map:
+		if m, ok := value.(map[string]interface{}); ok {
+			for k, v := range m {
+				m[k] = coerce(v, t.Elem())
+			}
+		}
+	case reflect.String:
+		switch v := value.(type) {
+		case int, int64, uint64, float64, bool:
+			return fmt.Sprint(v)
+		}
+	}
+	return value
+}
+
+// present 判断配置文件中是否出现了以点分隔的键
+func present(raw map[string]interface{}, key string) bool {
+	parts := strings.Split(key, ".")
+	for i, part := range parts {
+		value, ok := raw[part]
+		if !ok {
+			return false
+		}
+		if i == len(parts)-1 {
+			return true
+		}
+		if raw, ok = value.(map[string]interface{}); !ok {
+			return false
+		}
+	}
+	return false
+}
+
+// configFields 按 json 标签展开配置结构体
+func configFields(t reflect.Type, prefix string, index []int) []field {
+	var fields []field
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		name := jsonName(sf)
+		if name == "" {
+			continue
+		}
+		key := prefix + name
+		fieldIndex := append(append([]int{}, index...), i)
+		if sf.Type.Kind() == reflect.Struct {
+			fields = append(fields, configFields(sf.Type, key+".", fieldIndex)...)
+			continue
+		}
+		fields = append(fields, field{key: key, index: fieldIndex})
+	}
+	return fields
+}
+
+// jsonName 返回字段的 json 键，未导出或忽略的字段返回空
+func jsonName(sf reflect.StructField) string {
+	name, _, _ := strings.Cut(sf.Tag.Get("json"), ",")
+	if !sf.IsExported() || name == "-" {
+		return ""
+	}
+	return name
+}
+
+// envName 返回配置项对应的环境变量名
+func envName(key string) string {
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
+
+// set 将环境变量或命令行参数的文本值写入配置项
+// 字符串列表可以用逗号分隔，其他列表和映射以JSON表示，整体替换配置文件中的值
+func (c *Config) set(f field, raw string) error {
+	v := reflect.ValueOf(c).Elem().FieldByIndex(f.index)
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(raw)
+	case reflect.Int:
+		n, err := strconv.Atoi(strings.TrimSpace(raw))
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(n))
+	case reflect.Bool:
+		b, err := strconv.ParseBool(strings.TrimSpace(raw))
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Slice, reflect.Map:
+		if v.Type().Elem().Kind() == reflect.String && v.Kind() == reflect.Slice &&
+			!strings.HasPrefix(strings.TrimSpace(raw), "[") {
+			items := []string{}
+			for _, item := range strings.Split(raw, ",") {
+				if item = strings.TrimSpace(item); item != "" {
+					items = append(items, item)
+				}
+			}
+			v.Set(reflect.ValueOf(items))
+			return nil
+		}
+		value := reflect.New(v.Type())
+		if err := json.Unmarshal([]byte(raw), value.Interface()); err != nil {
+			return err
+		}
+		v.Set(value.Elem())
+	default:
+		return fmt.Errorf("不支持的配置类型: %s", v.Type())
+	}
+	return nil
+}
+
+// flagValue 配置项对应的命令行参数，布尔配置项可以省略值
+type flagValue struct {
+	kind reflect.Kind
+	set  func(string) error
+}
+
+func (v *flagValue) String() string     { return "" }
+func (v *flagValue) Set(s string) error { return v.set(s) }
+func (v *flagValue) IsBoolFlag() bool   { return v.kind == reflect.Bool }
+
+// Files 返回按加载顺序排列的配置文件路径，未使用配置文件时为空
+func (c *Config) Files() []string {
+	return c.files
+}
+
+// Settings 返回全部生效的配置项及其来源，密码和密钥以 ****** 代替
+func (c *Config) Settings() []Setting {
+	return c.settings(true)
+}
+
+// settings 返回全部配置项，redacted 为 false 时显示密钥明文
+func (c *Config) settings(redacted bool) []Setting {
+	keys := configFields(reflect.TypeOf(*c), "", nil)
+	settings := make([]Setting, 0, len(keys))
+	for _, f := range keys {
+		v := reflect.ValueOf(c).Elem().FieldByIndex(f.index)
+		settings = append(settings, Setting{
+			Key:    f.key,
+			Value:  c.formatValue(f.key, v, redacted),
+			Source: c.sources[f.key],
+		})
+	}
+	return settings
+}
+
+// WriteSettings 以表格形式输出全部生效的配置项及其来源，redacted 为 false 时显示密钥明文
+func (c *Config) WriteSettings(w io.Writer, redacted bool) error {
+	files := strings.Join(c.files, ", ")
+	if files == "" {
+		files = "(none)"
+	}
+	if _, err := fmt.Fprintf(w, "config files: %s\n\n", files); err != nil {
+		return err
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "KEY\tVALUE\tSOURCE")
+	for _, s := range c.settings(redacted) {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", s.Key, s.Value, s.Source)
+	}
+	return tw.Flush()
+}
+
+// maskedValue 敏感配置的显示值
+const maskedValue = "******"
+
+// formatValue 格式化配置值，Secret 字段自身会脱敏，通过引用解析的密钥同时显示引用
+func (c *Config) formatValue(key string, v reflect.Value, redacted bool) string {
+	if secret, ok := v.Interface().(Secret); ok {
+		if secret == "" {
+			return `""`
+		}
+		if !redacted {
+			return strconv.Quote(string(secret))
+		}
+		return c.maskSecret(key, secret)
+	}
+	switch v.Kind() {
+	case reflect.String:
+		return strconv.Quote(v.String())
+	case reflect.Slice, reflect.Map:
+		return c.formatJSON(key, v, redacted)
+	default:
+		return fmt.Sprint(v.Interface())
+	}
+}
+
+// maskSecret 返回脱敏后的密钥，通过引用解析的密钥同时显示引用
+func (c *Config) maskSecret(key string, secret Secret) string {
+	if ref, ok := c.secretRefs[key]; ok {
+		return fmt.Sprintf("%s (%s)", secret, ref)
+	}
+	return secret.String()
+}
+
+// formatJSON 以JSON格式输出列表、映射及其中的结构体，逐个元素处理，
+// 其中的 Secret 字段与顶层配置项一样按 redacted 决定是否脱敏，而不是使用总是脱敏的 Secret.MarshalJSON
+func (c *Config) formatJSON(key string, v reflect.Value, redacted bool) string {
+	if v.Type() == reflect.TypeOf(Secret("")) {
+		secret := Secret(v.String())
+		if redacted && secret != "" {
+			return jsonString(c.maskSecret(key, secret))
+		}
+		return jsonString(string(secret))
+	}
+
+	var parts []string
+	switch v.Kind() {
+	case reflect.Slice:
+		if v.IsNil() {
+			return "null"
+		}
+		for i := 0; i < v.Len(); i++ {
+			parts = append(parts, c.formatJSON(fmt.Sprintf("%s[%d]", key, i), v.Index(i), redacted))
+		}
+		return "[" + strings.Join(parts, ",") + "]"
+	case reflect.Map:
+		if v.IsNil() {
+			return "null"
+		}
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+		for _, k := range keys {
+			parts = append(parts, jsonString(k.String())+":"+c.formatJSON(key+"."+k.String(), v.MapIndex(k), redacted))
+		}
+		return "{" + strings.Join(parts, ",") + "}"
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if name := jsonName(v.Type().Field(i)); name != "" {
+				parts = append(parts, jsonString(name)+":"+c.formatJSON(key+"."+name, v.Field(i), redacted))
+			}
+		}
+		return "{" + strings.Join(parts, ",") + "}"
+	default:
+		data, err := json.Marshal(v.Interface())
+		if err != nil {
+			return err.Error()
+		}
+		return string(data)
+	}
+}
+
+// jsonString 返回字符串的JSON表示
+func jsonString(s string) string {
+	data, _ := json.Marshal(s)
+	return string(data)
+}
@@ -0,0 +1,69 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+// settingValue 返回配置项的显示值
+func settingValue(t *testing.T, c *Config, key string, redacted bool) string {
+	t.Helper()
+	for _, s := range c.settings(redacted) {
+		if s.Key == key {
+			return s.Value
+		}
+	}
+	t.Fatalf("setting %s not found", key)
+	return ""
+}
+
+func TestSettingsNestedSecrets(t *testing.T) {
+	c := &Config{
+		Database: DatabaseConfig{
+			Password: "primary-pw",
+			Replicas: []ReplicaConfig{{Host: "r1", Password: "replica-pw"}},
+			Shards: []ShardConfig{{
+				DBName:   "shard1",
+				Password: "shard-pw",
+				Replicas: []ReplicaConfig{{Host: "r2", Password: "shard-replica-pw"}},
+			}},
+		},
+		secretRefs: map[string]string{"database.replicas[0].password": "env:DB_REPLICA_PASSWORD"},
+	}
+
+	plain := map[string][]string{
+		"database.password": {"primary-pw"},
+		"database.replicas": {"replica-pw"},
+		"database.shards":   {"shard-pw", "shard-replica-pw"},
+	}
+	for key, secrets := range plain {
+		revealed := settingValue(t, c, key, false)
+		redacted := settingValue(t, c, key, true)
+		for _, secret := range secrets {
+			if !strings.Contains(revealed, secret) {
+				t.Errorf("%s with redacted=false = %s, want it to contain %s", key, revealed, secret)
+			}
+			if strings.Contains(redacted, secret) {
+				t.Errorf("%s with redacted=true = %s, leaks %s", key, redacted, secret)
+			}
+		}
+		if !strings.Contains(redacted, maskedValue) {
+			t.Errorf("%s with redacted=true = %s, want masked value", key, redacted)
+		}
+	}
+
+	want := `[{"host":"r1","port":"","user":"","password":"****** (env:DB_REPLICA_PASSWORD)","dbname":""}]`
+	if got := settingValue(t, c, "database.replicas", true); got != want {
+		t.Errorf("database.replicas = %s, want %s", got, want)
+	}
+}
+
+func TestSettingsEmptyCollections(t *testing.T) {
+	c := &Config{Database: DatabaseConfig{Replicas: []ReplicaConfig{}}}
+	if got := settingValue(t, c, "database.replicas", true); got != "[]" {
+		t.Errorf("database.replicas = %s, want []", got)
+	}
+	if got := settingValue(t, c, "database.shards", true); got != "null" {
+		t.Errorf("database.shards = %s, want null", got)
+	}
+}
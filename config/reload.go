@@ -0,0 +1,173 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// reloadableKeys 运行时可以重新加载的配置项，其余配置项（端口、数据库连接等）修改后需要重启
+var reloadableKeys = map[string]bool{
+	"log.level":                     true,
+	"redis.expire_hours":            true,
+	"redis.policies":                true,
+	"database.slow_query_ms":        true,
+	"database.log_queries":          true,
+	"database.log_query_params":     true,
+	"database.explain_slow_queries": true,
+}
+
+// reloadDebounce 配置文件变化后等待的时间，编辑器保存时可能分多次写入
+const reloadDebounce = 500 * time.Millisecond
+
+// Reloader 在配置文件变化或收到 SIGHUP 时重新加载配置，验证通过后替换当前配置并通知订阅者
+type Reloader struct {
+	mu          sync.Mutex
+	current     atomic.Pointer[Config]
+	subscribers []func(cfg *Config)
+}
+
+// NewReloader 以启动时加载的配置创建 Reloader
+func NewReloader(cfg *Config) *Reloader {
+	r := &Reloader{}
+	r.current.Store(cfg)
+	return r
+}
+
+// Current 返回当前生效的配置
+func (r *Reloader) Current() *Config {
+	return r.current.Load()
+}
+
+// Subscribe 注册配置变化的回调，回调在重新加载的协程中依次执行，不应阻塞
+func (r *Reloader) Subscribe(fn func(cfg *Config)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.subscribers = append(r.subscribers, fn)
+}
+
+// Reload 按启动时的参数重新加载配置，只应用可重新加载的配置项
+// 不可重新加载的配置项发生变化时记录日志并保持原值，加载或验证失败时保持当前配置
+func (r *Reloader) Reload() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current := r.current.Load()
+	loaded, _, err := Load(current.args)
+	if err != nil {
+		return err
+	}
+
+	next := *current
+	next.files = loaded.files
+	next.sources = make(map[string]Source, len(current.sources))
+	for key, source := range current.sources {
+		next.sources[key] = source
+	}
+
+	var changed []string
+	for _, f := range configFields(reflect.TypeOf(next), "", nil) {
+		from := reflect.ValueOf(current).Elem().FieldByIndex(f.index)
+		to := reflect.ValueOf(loaded).Elem().FieldByIndex(f.index)
+		if reflect.DeepEqual(from.Interface(), to.Interface()) {
+			continue
+		}
+		if !reloadableKeys[f.key] {
+			log.Printf("Config reload: %s changed but cannot be reloaded, restart to apply it", f.key)
+			continue
+		}
+		reflect.ValueOf(&next).Elem().FieldByIndex(f.index).Set(to)
+		next.sources[f.key] = loaded.sources[f.key]
+		changed = append(changed, f.key)
+	}
+	if len(changed) == 0 {
+		log.Printf("Config reload: no reloadable changes")
+		return nil
+	}
+	if err := next.validate(); err != nil {
+		return fmt.Errorf("配置验证失败: %w", err)
+	}
+
+	r.current.Store(&next)
+	log.Printf("Config reloaded: %s", strings.Join(changed, ", "))
+	for _, fn := range r.subscribers {
+		fn(&next)
+	}
+	return nil
+}
+
+// Run 监听配置文件和 SIGHUP 信号直到 ctx 结束
+func (r *Reloader) Run(ctx context.Context) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	var events chan fsnotify.Event
+	var watchErrors chan error
+	names := map[string]bool{}
+	if files := r.Current().files; len(files) > 0 {
+		watcher, err := fsnotify.NewWatcher()
+		if err != nil {
+			log.Printf("Config watcher disabled: %v", err)
+		} else {
+			defer watcher.Close()
+			// 监听目录而不是文件：编辑器和 Kubernetes ConfigMap 通过替换文件或符号链接更新配置
+			for _, file := range files {
+				names[filepath.Base(file)] = true
+				if err := watcher.Add(filepath.Dir(file)); err != nil {
+					log.Printf("Failed to watch config directory %s: %v", filepath.Dir(file), err)
+				}
+			}
+			names["..data"] = true
+			events, watchErrors = watcher.Events, watcher.Errors
+		}
+	}
+
+	debounce := time.NewTimer(reloadDebounce)
+	debounce.Stop()
+	defer debounce.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			log.Printf("Received SIGHUP, reloading config")
+			r.reload()
+		case event, ok := <-events:
+			if !ok {
+				events = nil
+				continue
+			}
+			if names[filepath.Base(event.Name)] {
+				debounce.Reset(reloadDebounce)
+			}
+		case err, ok := <-watchErrors:
+			if !ok {
+				watchErrors = nil
+				continue
+			}
+			log.Printf("Config watcher error: %v", err)
+		case <-debounce.C:
+			log.Printf("Config file changed, reloading config")
+			r.reload()
+		}
+	}
+}
+
+func (r *Reloader) reload() {
+	if err := r.Reload(); err != nil {
+		log.Printf("Config reload failed, keeping the current config: %v", err)
+	}
+}
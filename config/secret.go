@@ -0,0 +1,132 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// Secret 密码、密钥等敏感配置，打印、序列化和写入日志时以 ****** 代替
+// 配置值可以是明文，也可以是 file:///run/secrets/db_password、env:DB_PASS 形式的引用，加载配置时解析
+type Secret string
+
+func (s Secret) String() string {
+	if s == "" {
+		return ""
+	}
+	return maskedValue
+}
+
+func (s Secret) GoString() string {
+	return fmt.Sprintf("%q", s.String())
+}
+
+func (s Secret) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.String())
+}
+
+func (s Secret) LogValue() slog.Value {
+	return slog.StringValue(s.String())
+}
+
+// SecretProvider 解析一种引用方案的密钥，ref 为去掉方案前缀后的部分
+type SecretProvider interface {
+	Resolve(ctx context.Context, ref string) (string, error)
+}
+
+var (
+	secretProvidersMu sync.RWMutex
+	secretProviders   = map[string]SecretProvider{
+		"file": fileSecretProvider{},
+		"env":  envSecretProvider{},
+	}
+)
+
+// RegisterSecretProvider 注册引用方案对应的密钥提供者，需在 Load 之前调用，同名方案会被覆盖
+func RegisterSecretProvider(scheme string, provider SecretProvider) {
+	secretProvidersMu.Lock()
+	defer secretProvidersMu.Unlock()
+	secretProviders[scheme] = provider
+}
+
+// secretProvider 返回配置值中引用方案对应的提供者，不是已注册方案的引用时视为明文
+func secretProvider(value string) (SecretProvider, string, string, bool) {
+	scheme, ref, ok := strings.Cut(value, ":")
+	if !ok {
+		return nil, "", "", false
+	}
+	secretProvidersMu.RLock()
+	provider, ok := secretProviders[scheme]
+	secretProvidersMu.RUnlock()
+	if !ok {
+		return nil, "", "", false
+	}
+	// file:///run/secrets/x 与 file:/run/secrets/x 等价
+	return provider, scheme, strings.TrimPrefix(ref, "//"), true
+}
+
+// fileSecretProvider 从文件读取密钥，去掉末尾换行，适用于 Docker/Kubernetes 挂载的 secret
+type fileSecretProvider struct{}
+
+func (fileSecretProvider) Resolve(_ context.Context, path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// envSecretProvider 从指定的环境变量读取密钥
+type envSecretProvider struct{}
+
+func (envSecretProvider) Resolve(_ context.Context, name string) (string, error) {
+	value, ok := os.LookupEnv(name)
+	if !ok {
+		return "", fmt.Errorf("环境变量%s未设置", name)
+	}
+	return value, nil
+}
+
+// resolveSecrets 解析全部 Secret 字段中的引用，记录每个配置项使用的引用，返回全部解析失败的配置项
+func (c *Config) resolveSecrets(ctx context.Context) error {
+	c.secretRefs = map[string]string{}
+	var errs []error
+	var walk func(v reflect.Value, key string)
+	walk = func(v reflect.Value, key string) {
+		switch v.Kind() {
+		case reflect.Struct:
+			for i := 0; i < v.NumField(); i++ {
+				if name := jsonName(v.Type().Field(i)); name != "" {
+					walk(v.Field(i), strings.TrimPrefix(key+"."+name, "."))
+				}
+			}
+		case reflect.Slice:
+			for i := 0; i < v.Len(); i++ {
+				walk(v.Index(i), fmt.Sprintf("%s[%d]", key, i))
+			}
+		case reflect.String:
+			if v.Type() != reflect.TypeOf(Secret("")) {
+				return
+			}
+			provider, scheme, ref, ok := secretProvider(v.String())
+			if !ok {
+				return
+			}
+			value, err := provider.Resolve(ctx, ref)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("解析密钥%s失败(%s:%s): %w", key, scheme, ref, err))
+				return
+			}
+			c.secretRefs[key] = v.String()
+			v.SetString(value)
+		}
+	}
+	walk(reflect.ValueOf(c).Elem(), "")
+	return errors.Join(errs...)
+}
@@ -1,50 +1,254 @@
 package database
 
 import (
+	"context"
+	"database/sql"
 	"fmt"
+	"log"
 	"order_api/config"
-	"order_api/model"
 	"time"
 
+	"github.com/glebarez/sqlite"
 	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"gorm.io/plugin/dbresolver"
 )
 
+// maxRetryBackoff 连接重试的最大等待时间
+const maxRetryBackoff = 30 * time.Second
+
+// Database 数据库连接，配置了只读副本时查询自动路由到副本，写入和事务始终使用主库
 type Database struct {
 	*gorm.DB
+	name     string
+	logging  *queryLogSettings
+	primary  *gorm.DB
+	replicas []*sql.DB
+	Sticky   *StickyTracker
 }
 
-func NewDatabase(cfg *config.DatabaseConfig) (*Database, error) {
-	dsn := fmt.Sprintf(
-		"%s:%s@tcp(%s:%s)/%s?charset=%s&parseTime=True&loc=Local",
-		cfg.User, cfg.Password, cfg.Host, cfg.Port, cfg.DBName, cfg.Charset,
-	)
-
-	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{})
+// NewDatabase 连接数据库，name 用于区分日志和指标中的数据库
+func NewDatabase(cfg *config.DatabaseConfig, name string) (*Database, error) {
+	logging := newQueryLogSettings(cfg)
+	db, err := connect(cfg, newSQLLogger(name, logging))
 	if err != nil {
-		return nil, fmt.Errorf("failed to connect to database: %w", err)
+		return nil, err
 	}
 
 	sqlDB, err := db.DB()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get database instance: %w", err)
 	}
+	configurePool(sqlDB, cfg)
+
+	database := &Database{
+		DB:      db,
+		name:    name,
+		logging: logging,
+		primary: db,
+		Sticky:  NewStickyTracker(time.Duration(cfg.ReplicaStickySeconds) * time.Second),
+	}
+	if len(cfg.Replicas) > 0 {
+		if err := database.useReplicas(cfg, sqlDB); err != nil {
+			database.Close()
+			return nil, err
+		}
+	}
+	if err := instrument(database.DB, cfg.GetDriver(), name, logging); err != nil {
+		database.Close()
+		return nil, fmt.Errorf("failed to register query instrumentation: %w", err)
+	}
+
+	// 表结构由版本化迁移管理，见 Migrator
+	return database, nil
+}
+
+// useReplicas 连接只读副本并注册读写分离插件
+// 插件注册在共享主库连接池的独立会话上，Primary 返回的实例不受路由影响，供迁移等需要固定连接的场景使用
+func (db *Database) useReplicas(cfg *config.DatabaseConfig, primary *sql.DB) error {
+	replicas := make([]gorm.Dialector, 0, len(cfg.Replicas))
+	for i := range cfg.Replicas {
+		replicaCfg := cfg.ReplicaConfig(i)
+		replica, err := connect(&replicaCfg, db.primary.Logger)
+		if err != nil {
+			return fmt.Errorf("failed to connect to replica %d: %w", i+1, err)
+		}
+		sqlDB, err := replica.DB()
+		if err != nil {
+			return fmt.Errorf("failed to get replica %d instance: %w", i+1, err)
+		}
+		configurePool(sqlDB, &replicaCfg)
+		db.replicas = append(db.replicas, sqlDB)
+
+		dialector, err := connDialector(cfg, sqlDB)
+		if err != nil {
+			return err
+		}
+		replicas = append(replicas, dialector)
+	}
+
+	dialector, err := connDialector(cfg, primary)
+	if err != nil {
+		return err
+	}
+	routed, err := gorm.Open(dialector, &gorm.Config{DisableAutomaticPing: true, Logger: db.primary.Logger})
+	if err != nil {
+		return fmt.Errorf("failed to open routed database: %w", err)
+	}
+	err = routed.Use(dbresolver.Register(dbresolver.Config{
+		Replicas: replicas,
+		Policy:   &RoundRobinPolicy{},
+	}))
+	if err != nil {
+		return fmt.Errorf("failed to register replica resolver: %w", err)
+	}
+
+	db.DB = routed
+	return nil
+}
+
+// connect 建立数据库连接，失败时按指数退避重试，避免数据库晚于服务启动时直接退出
+func connect(cfg *config.DatabaseConfig, sqlLog logger.Interface) (*gorm.DB, error) {
+	backoff := time.Duration(cfg.RetryBackoff) * time.Second
+	if backoff <= 0 {
+		backoff = time.Second
+	}
+
+	for attempt := 0; ; attempt++ {
+		db, err := open(cfg, sqlLog)
+		if err == nil {
+			return db, nil
+		}
+		if attempt >= cfg.ConnectRetries {
+			return nil, fmt.Errorf("failed to connect to database after %d attempts: %w", attempt+1, err)
+		}
+
+		log.Printf("Failed to connect to database (attempt %d/%d), retrying in %s: %v", attempt+1, cfg.ConnectRetries+1, backoff, err)
+		time.Sleep(backoff)
+		if backoff *= 2; backoff > maxRetryBackoff {
+			backoff = maxRetryBackoff
+		}
+	}
+}
+
+// open 打开数据库并在超时时间内完成连通性检查
+func open(cfg *config.DatabaseConfig, sqlLog logger.Interface) (*gorm.DB, error) {
+	dialector, err := newDialector(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := gorm.Open(dialector, &gorm.Config{DisableAutomaticPing: true, Logger: sqlLog})
+	if err != nil {
+		return nil, err
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(cfg.GetConnectTimeout())*time.Second)
+	defer cancel()
+	if err := sqlDB.PingContext(ctx); err != nil {
+		sqlDB.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// configurePool 按配置设置连接池参数
+func configurePool(sqlDB *sql.DB, cfg *config.DatabaseConfig) {
+	maxIdle, maxOpen := cfg.MaxIdleConns, cfg.MaxOpenConns
+	if maxIdle <= 0 {
+		maxIdle = 10
+	}
+	if maxOpen <= 0 {
+		maxOpen = 100
+	}
+	sqlDB.SetMaxIdleConns(maxIdle)
+	sqlDB.SetMaxOpenConns(maxOpen)
+	sqlDB.SetConnMaxLifetime(time.Duration(cfg.ConnMaxLifetime) * time.Second)
+	sqlDB.SetConnMaxIdleTime(time.Duration(cfg.ConnMaxIdleTime) * time.Second)
 
-	sqlDB.SetMaxIdleConns(10)
-	sqlDB.SetMaxOpenConns(100)
-	sqlDB.SetConnMaxLifetime(time.Hour)
+	// SQLite 同一时间只允许一个写入者，使用单个常驻连接避免 database is locked，
+	// 同时保证内存数据库不会因连接回收而丢失
+	if cfg.GetDriver() == config.DriverSQLite {
+		sqlDB.SetMaxOpenConns(1)
+		sqlDB.SetConnMaxLifetime(0)
+		sqlDB.SetConnMaxIdleTime(0)
+	}
+}
 
-	if err := db.AutoMigrate(&model.Order{}, &model.OrderItem{}); err != nil {
-		return nil, fmt.Errorf("failed to migrate database: %w", err)
+// newDialector 根据配置的驱动创建GORM方言
+func newDialector(cfg *config.DatabaseConfig) (gorm.Dialector, error) {
+	switch cfg.GetDriver() {
+	case config.DriverMySQL:
+		return mysql.Open(cfg.GetDSN()), nil
+	case config.DriverPostgres:
+		return postgres.Open(cfg.GetDSN()), nil
+	case config.DriverSQLite:
+		return sqlite.Open(cfg.GetDSN()), nil
+	default:
+		return nil, fmt.Errorf("unsupported database driver: %s", cfg.Driver)
 	}
+}
 
-	return &Database{db}, nil
+// connDialector 基于已建立的连接池创建GORM方言
+func connDialector(cfg *config.DatabaseConfig, conn *sql.DB) (gorm.Dialector, error) {
+	switch cfg.GetDriver() {
+	case config.DriverMySQL:
+		return mysql.New(mysql.Config{Conn: conn}), nil
+	case config.DriverPostgres:
+		return postgres.New(postgres.Config{Conn: conn}), nil
+	case config.DriverSQLite:
+		return &sqlite.Dialector{Conn: conn}, nil
+	default:
+		return nil, fmt.Errorf("unsupported database driver: %s", cfg.Driver)
+	}
+}
+
+// Name 返回数据库名称，用于日志和指标
+func (db *Database) Name() string {
+	return db.name
+}
+
+// SetQueryLogging 在运行时调整慢查询阈值、SQL日志和 EXPLAIN 设置，不影响已建立的连接
+func (db *Database) SetQueryLogging(cfg *config.DatabaseConfig) {
+	db.logging.apply(cfg)
+}
+
+// Primary 返回直连主库的实例，不经过读写分离路由
+func (db *Database) Primary() *gorm.DB {
+	return db.primary
 }
 
 func (db *Database) Close() error {
-	sqlDB, err := db.DB.DB()
+	for _, replica := range db.replicas {
+		if err := replica.Close(); err != nil {
+			log.Printf("Error closing replica connection: %v", err)
+		}
+	}
+
+	sqlDB, err := db.primary.DB()
 	if err != nil {
 		return err
 	}
 	return sqlDB.Close()
-}
\ No newline at end of file
+}
+
+// Stats 返回连接池统计信息
+func (db *Database) Stats() (sql.DBStats, error) {
+	sqlDB, err := db.primary.DB()
+	if err != nil {
+		return sql.DBStats{}, err
+	}
+	return sqlDB.Stats(), nil
+}
+
+// Replicas 返回只读副本的连接池，顺序与配置一致
+func (db *Database) Replicas() []*sql.DB {
+	return db.replicas
+}
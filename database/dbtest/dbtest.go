@@ -0,0 +1,69 @@
+// Package dbtest 为测试创建执行过全部迁移的 SQLite 数据库，测试不依赖外部数据库
+package dbtest
+
+import (
+	"context"
+	"fmt"
+	"order_api/config"
+	"order_api/database"
+	"path/filepath"
+	"testing"
+)
+
+// memoryDSN 私有的内存数据库，SQLite 连接池只保留一个常驻连接，测试期间数据不会丢失
+const memoryDSN = "file::memory:"
+
+// Config 返回使用 SQLite 的数据库配置，dbNames 依次为0号分片和附加分片的数据库
+func Config(dbNames ...string) *config.DatabaseConfig {
+	cfg := &config.DatabaseConfig{
+		Driver:      config.DriverSQLite,
+		DBName:      dbNames[0],
+		SlowQueryMs: -1,
+	}
+	for _, name := range dbNames[1:] {
+		cfg.Shards = append(cfg.Shards, config.ShardConfig{DBName: name})
+	}
+	return cfg
+}
+
+// NewCluster 创建 shards 个分片的内存数据库集群，每个分片是独立的数据库，测试结束时关闭
+func NewCluster(t testing.TB, shards int) *database.Cluster {
+	t.Helper()
+	names := make([]string, shards)
+	for i := range names {
+		names[i] = memoryDSN
+	}
+	return Open(t, Config(names...))
+}
+
+// NewFileCluster 创建 shards 个分片的集群，每个分片是临时目录中的一个 SQLite 文件
+func NewFileCluster(t testing.TB, shards int) *database.Cluster {
+	t.Helper()
+	dir := t.TempDir()
+	names := make([]string, shards)
+	for i := range names {
+		names[i] = filepath.Join(dir, fmt.Sprintf("shard_%d.db", i))
+	}
+	return Open(t, Config(names...))
+}
+
+// Open 按配置连接集群并在每个分片上执行迁移，测试结束时关闭
+func Open(t testing.TB, cfg *config.DatabaseConfig) *database.Cluster {
+	t.Helper()
+	cluster, err := database.NewCluster(cfg)
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	t.Cleanup(func() { cluster.Close() })
+
+	for i, db := range cluster.Shards() {
+		migrator, err := database.NewMigrator(db.Primary())
+		if err != nil {
+			t.Fatalf("shard %d: %v", i, err)
+		}
+		if _, err := migrator.Up(context.Background()); err != nil {
+			t.Fatalf("failed to migrate shard %d: %v", i, err)
+		}
+	}
+	return cluster
+}
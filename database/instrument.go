@@ -0,0 +1,254 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"order_api/config"
+	"order_api/metrics"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// queryLogSettings SQL日志和诊断设置，同一数据库的日志器和回调共享，可在运行时调整
+type queryLogSettings struct {
+	slow    atomic.Int64 // 慢查询阈值（纳秒），0表示不记录慢查询
+	all     atomic.Bool  // 记录全部语句
+	params  atomic.Bool  // 记录参数值
+	explain atomic.Bool  // 对慢查询执行 EXPLAIN
+}
+
+func newQueryLogSettings(cfg *config.DatabaseConfig) *queryLogSettings {
+	s := &queryLogSettings{}
+	s.apply(cfg)
+	return s
+}
+
+func (s *queryLogSettings) apply(cfg *config.DatabaseConfig) {
+	s.slow.Store(int64(time.Duration(cfg.GetSlowQueryMs()) * time.Millisecond))
+	s.all.Store(cfg.LogQueries)
+	s.params.Store(cfg.LogQueryParams)
+	s.explain.Store(cfg.ExplainSlowQueries)
+}
+
+func (s *queryLogSettings) slowThreshold() time.Duration {
+	return time.Duration(s.slow.Load())
+}
+
+// sqlLogger 将 GORM 的SQL日志以结构化字段写入 slog，默认只记录失败和慢查询
+type sqlLogger struct {
+	name     string
+	settings *queryLogSettings
+	level    logger.LogLevel // 通过 LogMode 指定的级别，为0时由 log_queries 决定
+}
+
+func newSQLLogger(name string, settings *queryLogSettings) *sqlLogger {
+	return &sqlLogger{name: name, settings: settings}
+}
+
+func (l *sqlLogger) LogMode(level logger.LogLevel) logger.Interface {
+	clone := *l
+	clone.level = level
+	return &clone
+}
+
+func (l *sqlLogger) logLevel() logger.LogLevel {
+	switch {
+	case l.level != 0:
+		return l.level
+	case l.settings.all.Load():
+		return logger.Info
+	default:
+		return logger.Warn
+	}
+}
+
+func (l *sqlLogger) Info(ctx context.Context, msg string, args ...interface{}) {
+	if l.logLevel() >= logger.Info {
+		slog.InfoContext(ctx, fmt.Sprintf(msg, args...), "db", l.name)
+	}
+}
+
+func (l *sqlLogger) Warn(ctx context.Context, msg string, args ...interface{}) {
+	if l.logLevel() >= logger.Warn {
+		slog.WarnContext(ctx, fmt.Sprintf(msg, args...), "db", l.name)
+	}
+}
+
+func (l *sqlLogger) Error(ctx context.Context, msg string, args ...interface{}) {
+	if l.logLevel() >= logger.Error {
+		slog.ErrorContext(ctx, fmt.Sprintf(msg, args...), "db", l.name)
+	}
+}
+
+// Trace 记录执行失败的语句和慢查询，开启 log_queries 时记录全部语句
+func (l *sqlLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	elapsed := time.Since(begin)
+	level, slow := l.logLevel(), l.settings.slowThreshold()
+	switch {
+	case err != nil && level >= logger.Error && !errors.Is(err, gorm.ErrRecordNotFound):
+		query, rows := fc()
+		slog.ErrorContext(ctx, "sql failed", append(l.fields(elapsed, query, rows), "error", err)...)
+	case slow > 0 && elapsed > slow && level >= logger.Warn:
+		query, rows := fc()
+		slog.WarnContext(ctx, "slow sql", append(l.fields(elapsed, query, rows), "threshold_ms", slow.Milliseconds())...)
+	case level >= logger.Info:
+		query, rows := fc()
+		slog.InfoContext(ctx, "sql", l.fields(elapsed, query, rows)...)
+	}
+}
+
+func (l *sqlLogger) fields(elapsed time.Duration, query string, rows int64) []any {
+	return []any{
+		"db", l.name,
+		"duration_ms", float64(elapsed.Microseconds()) / 1000,
+		"rows", rows,
+		"sql", truncateSQL(query),
+	}
+}
+
+// maxLoggedSQL 日志中SQL的最大长度，批量 IN 查询的占位符列表可能非常长
+const maxLoggedSQL = 2048
+
+func truncateSQL(query string) string {
+	if len(query) <= maxLoggedSQL {
+		return query
+	}
+	return fmt.Sprintf("%s...(%d bytes truncated)", query[:maxLoggedSQL], len(query)-maxLoggedSQL)
+}
+
+// ParamsFilter 未开启 log_query_params 时丢弃参数，日志中的SQL保留占位符
+func (l *sqlLogger) ParamsFilter(ctx context.Context, query string, params ...interface{}) (string, []interface{}) {
+	if l.settings.params.Load() {
+		return query, params
+	}
+	return query, nil
+}
+
+// queryStartKey 语句开始时间在 Statement 中的键
+const queryStartKey = "order_api:query_start"
+
+// instrumentation 通过 GORM 回调记录每条语句的耗时指标，调试模式下对慢查询执行 EXPLAIN
+type instrumentation struct {
+	name     string
+	driver   string
+	settings *queryLogSettings
+}
+
+// instrument 在 db 上注册耗时统计回调
+func instrument(db *gorm.DB, driver, name string, settings *queryLogSettings) error {
+	in := &instrumentation{
+		name:     name,
+		driver:   driver,
+		settings: settings,
+	}
+
+	cb := db.Callback()
+	return errors.Join(
+		cb.Create().Before("gorm:create").Register("order_api:instrument_before_create", in.before),
+		cb.Create().After("gorm:create").Register("order_api:instrument_after_create", in.after("insert")),
+		cb.Query().Before("gorm:query").Register("order_api:instrument_before_query", in.before),
+		cb.Query().After("gorm:query").Register("order_api:instrument_after_query", in.after("select")),
+		cb.Update().Before("gorm:update").Register("order_api:instrument_before_update", in.before),
+		cb.Update().After("gorm:update").Register("order_api:instrument_after_update", in.after("update")),
+		cb.Delete().Before("gorm:delete").Register("order_api:instrument_before_delete", in.before),
+		cb.Delete().After("gorm:delete").Register("order_api:instrument_after_delete", in.after("delete")),
+		cb.Row().Before("gorm:row").Register("order_api:instrument_before_row", in.before),
+		cb.Row().After("gorm:row").Register("order_api:instrument_after_row", in.after("select")),
+		cb.Raw().Before("gorm:raw").Register("order_api:instrument_before_raw", in.before),
+		cb.Raw().After("gorm:raw").Register("order_api:instrument_after_raw", in.after("raw")),
+	)
+}
+
+func (in *instrumentation) before(db *gorm.DB) {
+	db.InstanceSet(queryStartKey, time.Now())
+}
+
+func (in *instrumentation) after(operation string) func(db *gorm.DB) {
+	return func(db *gorm.DB) {
+		value, ok := db.InstanceGet(queryStartKey)
+		if !ok {
+			return
+		}
+		elapsed := time.Since(value.(time.Time))
+
+		table := db.Statement.Table
+		if table == "" {
+			table = "unknown"
+		}
+		failed := db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound)
+		metrics.ObserveQuery(in.name, operation, table, elapsed, failed)
+
+		slow := in.settings.slowThreshold()
+		if in.settings.explain.Load() && operation == "select" && !failed && slow > 0 && elapsed > slow {
+			in.explainQuery(db, elapsed)
+		}
+	}
+}
+
+// explainQuery 在执行原语句的同一连接上获取执行计划并记录日志
+func (in *instrumentation) explainQuery(db *gorm.DB, elapsed time.Duration) {
+	ctx := db.Statement.Context
+	query := db.Statement.SQL.String()
+	if !strings.HasPrefix(strings.ToUpper(strings.TrimSpace(query)), "SELECT") {
+		return
+	}
+
+	prefix := "EXPLAIN "
+	if in.driver == config.DriverSQLite {
+		prefix = "EXPLAIN QUERY PLAN "
+	}
+	rows, err := db.Statement.ConnPool.QueryContext(ctx, prefix+query, db.Statement.Vars...)
+	if err != nil {
+		slog.WarnContext(ctx, "explain slow sql failed", "db", in.name, "sql", truncateSQL(query), "error", err)
+		return
+	}
+	defer rows.Close()
+
+	plan, err := formatPlan(rows)
+	if err != nil {
+		slog.WarnContext(ctx, "explain slow sql failed", "db", in.name, "sql", truncateSQL(query), "error", err)
+		return
+	}
+	slog.WarnContext(ctx, "slow sql plan",
+		"db", in.name,
+		"table", db.Statement.Table,
+		"duration_ms", float64(elapsed.Microseconds())/1000,
+		"sql", truncateSQL(query),
+		"plan", plan,
+	)
+}
+
+// formatPlan 将执行计划的每一行格式化为 column=value 形式，行之间以分号分隔
+func formatPlan(rows *sql.Rows) (string, error) {
+	columns, err := rows.Columns()
+	if err != nil {
+		return "", err
+	}
+
+	var lines []string
+	values := make([]sql.NullString, len(columns))
+	dest := make([]interface{}, len(columns))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return "", err
+		}
+		fields := make([]string, 0, len(columns))
+		for i, column := range columns {
+			if values[i].Valid {
+				fields = append(fields, column+"="+values[i].String)
+			}
+		}
+		lines = append(lines, strings.Join(fields, " "))
+	}
+	return strings.Join(lines, "; "), rows.Err()
+}
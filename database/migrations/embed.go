@@ -0,0 +1,12 @@
+// Package migrations 内嵌按版本编号的数据库迁移脚本
+//
+// 文件命名规则：<版本号>_<名称>.up.sql / <版本号>_<名称>.down.sql，
+// 版本号递增且不可修改已发布的脚本，结构变更一律新增脚本。
+package migrations
+
+import "embed"
+
+// FS 各数据库方言的迁移脚本
+//
+//go:embed mysql/*.sql postgres/*.sql sqlite/*.sql
+var FS embed.FS
@@ -0,0 +1,24 @@
+CREATE TABLE IF NOT EXISTS orders (
+    id         VARCHAR(36)    NOT NULL,
+    user_id    VARCHAR(36)    NOT NULL,
+    status     VARCHAR(20)    NOT NULL DEFAULT 'pending',
+    amount     DECIMAL(10, 2) NOT NULL DEFAULT 0,
+    created_at DATETIME(3)    NULL,
+    updated_at DATETIME(3)    NULL,
+    deleted_at DATETIME(3)    NULL,
+    PRIMARY KEY (id),
+    INDEX idx_orders_user_id (user_id),
+    INDEX idx_orders_deleted_at (deleted_at)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4;
+
+CREATE TABLE IF NOT EXISTS order_items (
+    id         VARCHAR(36)    NOT NULL,
+    order_id   VARCHAR(36)    NOT NULL,
+    product_id VARCHAR(36)    NOT NULL,
+    quantity   BIGINT         NOT NULL,
+    price      DECIMAL(10, 2) NOT NULL,
+    created_at DATETIME(3)    NULL,
+    updated_at DATETIME(3)    NULL,
+    PRIMARY KEY (id),
+    INDEX idx_order_items_order_id (order_id)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4;
@@ -0,0 +1 @@
+DROP INDEX idx_orders_updated_at ON orders;
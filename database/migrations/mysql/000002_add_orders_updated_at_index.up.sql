@@ -0,0 +1,2 @@
+-- 缓存预热按更新时间查询最近活跃的订单
+CREATE INDEX idx_orders_updated_at ON orders (updated_at);
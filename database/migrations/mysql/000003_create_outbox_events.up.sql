@@ -0,0 +1,16 @@
+-- 事务性发件箱：领域事件与订单变更在同一事务中写入，由投递器异步发布
+CREATE TABLE IF NOT EXISTS outbox_events (
+    id           BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    aggregate_id VARCHAR(36)     NOT NULL,
+    event_type   VARCHAR(64)     NOT NULL,
+    payload      TEXT            NOT NULL,
+    status       VARCHAR(20)     NOT NULL DEFAULT 'pending',
+    attempts     BIGINT          NOT NULL DEFAULT 0,
+    last_error   TEXT            NULL,
+    available_at DATETIME(3)     NOT NULL,
+    created_at   DATETIME(3)     NULL,
+    delivered_at DATETIME(3)     NULL,
+    PRIMARY KEY (id),
+    INDEX idx_outbox_events_status_available_at (status, available_at),
+    INDEX idx_outbox_events_aggregate_id (aggregate_id, status)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4;
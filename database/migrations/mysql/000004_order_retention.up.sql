@@ -0,0 +1,18 @@
+-- 订单项随订单软删除，便于恢复和按保留期清理
+ALTER TABLE order_items ADD COLUMN deleted_at DATETIME(3) NULL;
+CREATE INDEX idx_order_items_deleted_at ON order_items (deleted_at);
+
+-- 超过保留期的订单归档，订单项以 JSON 形式保存在同一行
+CREATE TABLE IF NOT EXISTS orders_archive (
+    id          VARCHAR(36)    NOT NULL,
+    user_id     VARCHAR(36)    NOT NULL,
+    status      VARCHAR(20)    NOT NULL,
+    amount      DECIMAL(10, 2) NOT NULL DEFAULT 0,
+    items       LONGTEXT       NOT NULL,
+    created_at  DATETIME(3)    NULL,
+    updated_at  DATETIME(3)    NULL,
+    deleted_at  DATETIME(3)    NULL,
+    archived_at DATETIME(3)    NOT NULL,
+    PRIMARY KEY (id),
+    INDEX idx_orders_archive_user_id (user_id)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4;
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS order_number_sequences;
+DROP INDEX idx_orders_archive_number ON orders_archive;
+ALTER TABLE orders_archive DROP COLUMN number;
+DROP INDEX idx_orders_number ON orders;
+ALTER TABLE orders DROP COLUMN number;
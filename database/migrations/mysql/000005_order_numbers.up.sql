@@ -0,0 +1,13 @@
+-- 面向用户的订单号，此前创建的订单没有订单号
+ALTER TABLE orders ADD COLUMN number VARCHAR(32) NULL;
+CREATE UNIQUE INDEX idx_orders_number ON orders (number);
+
+ALTER TABLE orders_archive ADD COLUMN number VARCHAR(32) NULL;
+CREATE INDEX idx_orders_archive_number ON orders_archive (number);
+
+-- 订单号按天计数，每个分片独立维护
+CREATE TABLE IF NOT EXISTS order_number_sequences (
+    scope VARCHAR(32) NOT NULL,
+    value BIGINT      NOT NULL DEFAULT 0,
+    PRIMARY KEY (scope)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4;
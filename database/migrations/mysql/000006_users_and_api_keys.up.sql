@@ -0,0 +1,26 @@
+-- 账号和 API 密钥，只使用0号分片中的表
+CREATE TABLE IF NOT EXISTS users (
+    id            VARCHAR(36)  NOT NULL,
+    username      VARCHAR(64)  NOT NULL,
+    password_hash VARCHAR(255) NOT NULL,
+    role          VARCHAR(20)  NOT NULL DEFAULT 'user',
+    disabled      BOOLEAN      NOT NULL DEFAULT FALSE,
+    created_at    DATETIME(3)  NULL,
+    updated_at    DATETIME(3)  NULL,
+    PRIMARY KEY (id),
+    UNIQUE INDEX idx_users_username (username)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4;
+
+-- 只保存密钥的 SHA-256 摘要，明文只在创建时显示一次
+CREATE TABLE IF NOT EXISTS api_keys (
+    id         VARCHAR(36) NOT NULL,
+    user_id    VARCHAR(36) NOT NULL,
+    name       VARCHAR(64) NOT NULL,
+    prefix     VARCHAR(16) NOT NULL,
+    key_hash   CHAR(64)    NOT NULL,
+    expires_at DATETIME(3) NULL,
+    created_at DATETIME(3) NULL,
+    PRIMARY KEY (id),
+    UNIQUE INDEX idx_api_keys_key_hash (key_hash),
+    INDEX idx_api_keys_user_id (user_id)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4;
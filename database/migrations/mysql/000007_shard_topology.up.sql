@@ -0,0 +1,8 @@
+-- 每个分片记录自己的序号和写入数据时的分片数量，启动时检查分片配置没有变化
+CREATE TABLE IF NOT EXISTS shard_topology (
+    id          INT      NOT NULL,
+    shard_index INT      NOT NULL,
+    shard_count INT      NOT NULL,
+    updated_at  DATETIME NULL,
+    PRIMARY KEY (id)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4;
@@ -0,0 +1,24 @@
+CREATE TABLE IF NOT EXISTS orders (
+    id         VARCHAR(36)    NOT NULL PRIMARY KEY,
+    user_id    VARCHAR(36)    NOT NULL,
+    status     VARCHAR(20)    NOT NULL DEFAULT 'pending',
+    amount     NUMERIC(10, 2) NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ    NULL,
+    updated_at TIMESTAMPTZ    NULL,
+    deleted_at TIMESTAMPTZ    NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_orders_user_id ON orders (user_id);
+CREATE INDEX IF NOT EXISTS idx_orders_deleted_at ON orders (deleted_at);
+
+CREATE TABLE IF NOT EXISTS order_items (
+    id         VARCHAR(36)    NOT NULL PRIMARY KEY,
+    order_id   VARCHAR(36)    NOT NULL,
+    product_id VARCHAR(36)    NOT NULL,
+    quantity   BIGINT         NOT NULL,
+    price      NUMERIC(10, 2) NOT NULL,
+    created_at TIMESTAMPTZ    NULL,
+    updated_at TIMESTAMPTZ    NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_order_items_order_id ON order_items (order_id);
@@ -0,0 +1,16 @@
+-- 事务性发件箱：领域事件与订单变更在同一事务中写入，由投递器异步发布
+CREATE TABLE IF NOT EXISTS outbox_events (
+    id           BIGSERIAL   NOT NULL PRIMARY KEY,
+    aggregate_id VARCHAR(36) NOT NULL,
+    event_type   VARCHAR(64) NOT NULL,
+    payload      TEXT        NOT NULL,
+    status       VARCHAR(20) NOT NULL DEFAULT 'pending',
+    attempts     BIGINT      NOT NULL DEFAULT 0,
+    last_error   TEXT        NULL,
+    available_at TIMESTAMPTZ NOT NULL,
+    created_at   TIMESTAMPTZ NULL,
+    delivered_at TIMESTAMPTZ NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_outbox_events_status_available_at ON outbox_events (status, available_at);
+CREATE INDEX IF NOT EXISTS idx_outbox_events_aggregate_id ON outbox_events (aggregate_id, status);
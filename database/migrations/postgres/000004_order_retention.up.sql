@@ -0,0 +1,18 @@
+-- 订单项随订单软删除，便于恢复和按保留期清理
+ALTER TABLE order_items ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ NULL;
+CREATE INDEX IF NOT EXISTS idx_order_items_deleted_at ON order_items (deleted_at);
+
+-- 超过保留期的订单归档，订单项以 JSON 形式保存在同一行
+CREATE TABLE IF NOT EXISTS orders_archive (
+    id          VARCHAR(36)    NOT NULL PRIMARY KEY,
+    user_id     VARCHAR(36)    NOT NULL,
+    status      VARCHAR(20)    NOT NULL,
+    amount      NUMERIC(10, 2) NOT NULL DEFAULT 0,
+    items       TEXT           NOT NULL,
+    created_at  TIMESTAMPTZ    NULL,
+    updated_at  TIMESTAMPTZ    NULL,
+    deleted_at  TIMESTAMPTZ    NULL,
+    archived_at TIMESTAMPTZ    NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_orders_archive_user_id ON orders_archive (user_id);
@@ -0,0 +1,7 @@
+-- 每个分片记录自己的序号和写入数据时的分片数量，启动时检查分片配置没有变化
+CREATE TABLE IF NOT EXISTS shard_topology (
+    id          INTEGER   NOT NULL PRIMARY KEY,
+    shard_index INTEGER   NOT NULL,
+    shard_count INTEGER   NOT NULL,
+    updated_at  TIMESTAMP NULL
+);
@@ -0,0 +1,24 @@
+CREATE TABLE IF NOT EXISTS orders (
+    id         TEXT     NOT NULL PRIMARY KEY,
+    user_id    TEXT     NOT NULL,
+    status     TEXT     NOT NULL DEFAULT 'pending',
+    amount     NUMERIC  NOT NULL DEFAULT 0,
+    created_at DATETIME NULL,
+    updated_at DATETIME NULL,
+    deleted_at DATETIME NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_orders_user_id ON orders (user_id);
+CREATE INDEX IF NOT EXISTS idx_orders_deleted_at ON orders (deleted_at);
+
+CREATE TABLE IF NOT EXISTS order_items (
+    id         TEXT     NOT NULL PRIMARY KEY,
+    order_id   TEXT     NOT NULL,
+    product_id TEXT     NOT NULL,
+    quantity   INTEGER  NOT NULL,
+    price      NUMERIC  NOT NULL,
+    created_at DATETIME NULL,
+    updated_at DATETIME NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_order_items_order_id ON order_items (order_id);
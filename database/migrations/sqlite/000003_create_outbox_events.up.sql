@@ -0,0 +1,16 @@
+-- 事务性发件箱：领域事件与订单变更在同一事务中写入，由投递器异步发布
+CREATE TABLE IF NOT EXISTS outbox_events (
+    id           INTEGER  NOT NULL PRIMARY KEY AUTOINCREMENT,
+    aggregate_id TEXT     NOT NULL,
+    event_type   TEXT     NOT NULL,
+    payload      TEXT     NOT NULL,
+    status       TEXT     NOT NULL DEFAULT 'pending',
+    attempts     INTEGER  NOT NULL DEFAULT 0,
+    last_error   TEXT     NULL,
+    available_at DATETIME NOT NULL,
+    created_at   DATETIME NULL,
+    delivered_at DATETIME NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_outbox_events_status_available_at ON outbox_events (status, available_at);
+CREATE INDEX IF NOT EXISTS idx_outbox_events_aggregate_id ON outbox_events (aggregate_id, status);
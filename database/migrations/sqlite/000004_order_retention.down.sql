@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS orders_archive;
+DROP INDEX IF EXISTS idx_order_items_deleted_at;
+ALTER TABLE order_items DROP COLUMN deleted_at;
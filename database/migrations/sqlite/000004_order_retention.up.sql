@@ -0,0 +1,18 @@
+-- 订单项随订单软删除，便于恢复和按保留期清理
+ALTER TABLE order_items ADD COLUMN deleted_at DATETIME NULL;
+CREATE INDEX IF NOT EXISTS idx_order_items_deleted_at ON order_items (deleted_at);
+
+-- 超过保留期的订单归档，订单项以 JSON 形式保存在同一行
+CREATE TABLE IF NOT EXISTS orders_archive (
+    id          TEXT     NOT NULL PRIMARY KEY,
+    user_id     TEXT     NOT NULL,
+    status      TEXT     NOT NULL,
+    amount      NUMERIC  NOT NULL DEFAULT 0,
+    items       TEXT     NOT NULL,
+    created_at  DATETIME NULL,
+    updated_at  DATETIME NULL,
+    deleted_at  DATETIME NULL,
+    archived_at DATETIME NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_orders_archive_user_id ON orders_archive (user_id);
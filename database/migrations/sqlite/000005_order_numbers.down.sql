@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS order_number_sequences;
+DROP INDEX IF EXISTS idx_orders_archive_number;
+ALTER TABLE orders_archive DROP COLUMN number;
+DROP INDEX IF EXISTS idx_orders_number;
+ALTER TABLE orders DROP COLUMN number;
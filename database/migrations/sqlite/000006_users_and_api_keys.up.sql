@@ -0,0 +1,26 @@
+-- 账号和 API 密钥，只使用0号分片中的表
+CREATE TABLE IF NOT EXISTS users (
+    id            TEXT     NOT NULL PRIMARY KEY,
+    username      TEXT     NOT NULL,
+    password_hash TEXT     NOT NULL,
+    role          TEXT     NOT NULL DEFAULT 'user',
+    disabled      BOOLEAN  NOT NULL DEFAULT FALSE,
+    created_at    DATETIME NULL,
+    updated_at    DATETIME NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_username ON users (username);
+
+-- 只保存密钥的 SHA-256 摘要，明文只在创建时显示一次
+CREATE TABLE IF NOT EXISTS api_keys (
+    id         TEXT     NOT NULL PRIMARY KEY,
+    user_id    TEXT     NOT NULL,
+    name       TEXT     NOT NULL,
+    prefix     TEXT     NOT NULL,
+    key_hash   TEXT     NOT NULL,
+    expires_at DATETIME NULL,
+    created_at DATETIME NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_api_keys_key_hash ON api_keys (key_hash);
+CREATE INDEX IF NOT EXISTS idx_api_keys_user_id ON api_keys (user_id);
@@ -0,0 +1,377 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"order_api/database/migrations"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// migrationLockName 迁移使用的数据库咨询锁名称，防止多个副本同时执行迁移
+const migrationLockName = "order_api_schema_migrations"
+
+var migrationFilePattern = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// Migration 单个版本的迁移脚本
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// MigrationStatus 迁移执行状态
+type MigrationStatus struct {
+	Version   int64
+	Name      string
+	Applied   bool
+	Dirty     bool
+	AppliedAt *time.Time
+}
+
+// schemaMigration schema_migrations 表的记录
+type schemaMigration struct {
+	Version   int64  `gorm:"primaryKey;autoIncrement:false"`
+	Name      string `gorm:"size:255"`
+	Dirty     bool
+	AppliedAt time.Time
+}
+
+func (schemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// Migrator 基于内嵌SQL脚本的版本化迁移
+type Migrator struct {
+	db         *gorm.DB
+	migrations []Migration
+}
+
+// NewMigrator 加载当前数据库方言的迁移脚本
+func NewMigrator(db *gorm.DB) (*Migrator, error) {
+	list, err := loadMigrations(migrations.FS, db.Dialector.Name())
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, migrations: list}, nil
+}
+
+// loadMigrations 读取并按版本排序迁移脚本
+func loadMigrations(fsys fs.FS, dialect string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dialect)
+	if err != nil {
+		return nil, fmt.Errorf("读取迁移脚本失败: %w", err)
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, entry := range entries {
+		match := migrationFilePattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("无效的迁移脚本文件名: %s", entry.Name())
+		}
+		version, _ := strconv.ParseInt(match[1], 10, 64)
+		data, err := fs.ReadFile(fsys, path.Join(dialect, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("读取迁移脚本失败: %w", err)
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		} else if m.Name != match[2] {
+			return nil, fmt.Errorf("迁移版本 %d 存在多个名称: %s, %s", version, m.Name, match[2])
+		}
+		if match[3] == "up" {
+			m.Up = string(data)
+		} else {
+			m.Down = string(data)
+		}
+	}
+
+	list := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("迁移版本 %d 缺少 up 脚本", m.Version)
+		}
+		list = append(list, *m)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Version < list[j].Version })
+	return list, nil
+}
+
+// Latest 返回最新的迁移版本号
+func (m *Migrator) Latest() int64 {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+// Up 执行全部未应用的迁移，返回本次应用的迁移
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	var applied []Migration
+	err := m.withLock(ctx, true, func(conn *gorm.DB) error {
+		current, err := m.appliedVersions(conn)
+		if err != nil {
+			return err
+		}
+		for _, migration := range m.migrations {
+			if _, ok := current[migration.Version]; ok {
+				continue
+			}
+			if err := m.apply(conn, migration, true); err != nil {
+				return err
+			}
+			applied = append(applied, migration)
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// Down 回滚最近的 steps 个迁移，返回本次回滚的迁移
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	var reverted []Migration
+	err := m.withLock(ctx, true, func(conn *gorm.DB) error {
+		current, err := m.appliedVersions(conn)
+		if err != nil {
+			return err
+		}
+		for i := len(m.migrations) - 1; i >= 0 && len(reverted) < steps; i-- {
+			migration := m.migrations[i]
+			if _, ok := current[migration.Version]; !ok {
+				continue
+			}
+			if migration.Down == "" {
+				return fmt.Errorf("迁移版本 %d 没有 down 脚本，无法回滚", migration.Version)
+			}
+			if err := m.apply(conn, migration, false); err != nil {
+				return err
+			}
+			reverted = append(reverted, migration)
+		}
+		return nil
+	})
+	return reverted, err
+}
+
+// Force 将指定版本标记为已成功应用，用于人工修复后清除 dirty 状态
+func (m *Migrator) Force(ctx context.Context, version int64) error {
+	return m.withLock(ctx, false, func(conn *gorm.DB) error {
+		for _, migration := range m.migrations {
+			if migration.Version == version {
+				return saveRecord(conn, &schemaMigration{
+					Version:   version,
+					Name:      migration.Name,
+					AppliedAt: time.Now(),
+				})
+			}
+		}
+		return fmt.Errorf("迁移版本 %d 不存在", version)
+	})
+}
+
+// Status 返回所有迁移的执行状态
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	conn := m.db.WithContext(ctx)
+	if err := m.ensureTable(conn); err != nil {
+		return nil, err
+	}
+	current, err := m.appliedVersions(conn)
+	if err != nil {
+		return nil, err
+	}
+
+	status := make([]MigrationStatus, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		s := MigrationStatus{Version: migration.Version, Name: migration.Name}
+		if record, ok := current[migration.Version]; ok {
+			s.Applied = !record.Dirty
+			s.Dirty = record.Dirty
+			s.AppliedAt = &record.AppliedAt
+		}
+		status = append(status, s)
+	}
+	return status, nil
+}
+
+// Pending 返回尚未应用的迁移数量
+func (m *Migrator) Pending(ctx context.Context) (int, error) {
+	status, err := m.Status(ctx)
+	if err != nil {
+		return 0, err
+	}
+	pending := 0
+	for _, s := range status {
+		if !s.Applied {
+			pending++
+		}
+	}
+	return pending, nil
+}
+
+// apply 执行单个迁移脚本
+// MySQL 的DDL不支持事务，为各方言统一处理，执行前先写入 dirty 记录，失败时保留以便人工处理
+func (m *Migrator) apply(conn *gorm.DB, migration Migration, up bool) error {
+	script := migration.Up
+	if !up {
+		script = migration.Down
+	}
+
+	record := &schemaMigration{
+		Version:   migration.Version,
+		Name:      migration.Name,
+		Dirty:     true,
+		AppliedAt: time.Now(),
+	}
+	if err := saveRecord(conn, record); err != nil {
+		return fmt.Errorf("记录迁移状态失败: %w", err)
+	}
+
+	for _, stmt := range splitStatements(script) {
+		if err := conn.Exec(stmt).Error; err != nil {
+			direction := "up"
+			if !up {
+				direction = "down"
+			}
+			return fmt.Errorf("执行迁移 %d_%s (%s) 失败: %w", migration.Version, migration.Name, direction, err)
+		}
+	}
+
+	if !up {
+		return conn.Delete(record).Error
+	}
+	return conn.Model(record).Update("dirty", false).Error
+}
+
+// saveRecord 写入或覆盖迁移记录
+func saveRecord(conn *gorm.DB, record *schemaMigration) error {
+	return conn.Clauses(clause.OnConflict{UpdateAll: true}).Create(record).Error
+}
+
+// appliedVersions 读取已记录的迁移
+func (m *Migrator) appliedVersions(conn *gorm.DB) (map[int64]schemaMigration, error) {
+	var records []schemaMigration
+	if err := conn.Order("version").Find(&records).Error; err != nil {
+		return nil, fmt.Errorf("读取迁移记录失败: %w", err)
+	}
+
+	applied := make(map[int64]schemaMigration, len(records))
+	for _, record := range records {
+		applied[record.Version] = record
+	}
+	return applied, nil
+}
+
+// checkDirty 存在未完成的迁移时返回错误
+func checkDirty(applied map[int64]schemaMigration) error {
+	for version, record := range applied {
+		if record.Dirty {
+			return fmt.Errorf("迁移版本 %d 处于 dirty 状态，请人工确认后执行 migrate force %d", version, version)
+		}
+	}
+	return nil
+}
+
+// ensureTable 创建 schema_migrations 表
+func (m *Migrator) ensureTable(conn *gorm.DB) error {
+	if err := conn.AutoMigrate(&schemaMigration{}); err != nil {
+		return fmt.Errorf("创建迁移记录表失败: %w", err)
+	}
+	return nil
+}
+
+// withLock 在单个数据库连接上获取咨询锁后执行迁移，rejectDirty 为 true 时存在 dirty 记录则拒绝执行
+func (m *Migrator) withLock(ctx context.Context, rejectDirty bool, fn func(conn *gorm.DB) error) error {
+	return m.db.WithContext(ctx).Connection(func(conn *gorm.DB) error {
+		// 咨询锁绑定在连接上，跳过默认事务，避免提交后GORM将语句切回连接池
+		conn = conn.Session(&gorm.Session{SkipDefaultTransaction: true})
+
+		if err := acquireLock(conn); err != nil {
+			return err
+		}
+		defer releaseLock(conn)
+
+		if err := m.ensureTable(conn); err != nil {
+			return err
+		}
+		if rejectDirty {
+			applied, err := m.appliedVersions(conn)
+			if err != nil {
+				return err
+			}
+			if err := checkDirty(applied); err != nil {
+				return err
+			}
+		}
+		return fn(conn)
+	})
+}
+
+// acquireLock 获取咨询锁，最多等待一分钟
+func acquireLock(conn *gorm.DB) error {
+	switch conn.Dialector.Name() {
+	case "mysql":
+		var locked int
+		if err := conn.Raw("SELECT GET_LOCK(?, 60)", migrationLockName).Scan(&locked).Error; err != nil {
+			return fmt.Errorf("获取迁移锁失败: %w", err)
+		}
+		if locked != 1 {
+			return fmt.Errorf("获取迁移锁超时，可能有其他实例正在执行迁移")
+		}
+	case "postgres":
+		if err := conn.Exec("SET lock_timeout = '60s'").Error; err != nil {
+			return fmt.Errorf("获取迁移锁失败: %w", err)
+		}
+		if err := conn.Exec("SELECT pg_advisory_lock(hashtext(?))", migrationLockName).Error; err != nil {
+			return fmt.Errorf("获取迁移锁失败: %w", err)
+		}
+	}
+	// SQLite 为单机文件数据库，写操作本身互斥，不需要咨询锁
+	return nil
+}
+
+// releaseLock 释放咨询锁
+func releaseLock(conn *gorm.DB) {
+	switch conn.Dialector.Name() {
+	case "mysql":
+		conn.Exec("SELECT RELEASE_LOCK(?)", migrationLockName)
+	case "postgres":
+		conn.Exec("SELECT pg_advisory_unlock(hashtext(?))", migrationLockName)
+		conn.Exec("RESET lock_timeout")
+	}
+}
+
+// splitStatements 按分号拆分脚本中的SQL语句，忽略注释行
+func splitStatements(script string) []string {
+	var (
+		statements []string
+		current    strings.Builder
+	)
+	for _, line := range strings.Split(script, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+		current.WriteString(line)
+		current.WriteString("\n")
+		if strings.HasSuffix(trimmed, ";") {
+			statements = append(statements, strings.TrimSuffix(strings.TrimSpace(current.String()), ";"))
+			current.Reset()
+		}
+	}
+	if rest := strings.TrimSpace(current.String()); rest != "" {
+		statements = append(statements, rest)
+	}
+	return statements
+}
@@ -0,0 +1,98 @@
+package database
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RoundRobinPolicy 在只读副本间轮询分配查询
+type RoundRobinPolicy struct {
+	next uint64
+}
+
+func (p *RoundRobinPolicy) Resolve(connPools []gorm.ConnPool) gorm.ConnPool {
+	n := atomic.AddUint64(&p.next, 1)
+	return connPools[(n-1)%uint64(len(connPools))]
+}
+
+type primaryKey struct{}
+
+// WithPrimary 标记该上下文中的查询必须读取主库，用于先读后写等需要最新数据的场景
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryKey{}, true)
+}
+
+// UsePrimary 判断上下文是否要求读取主库
+func UsePrimary(ctx context.Context) bool {
+	primary, _ := ctx.Value(primaryKey{}).(bool)
+	return primary
+}
+
+// StickyTracker 记录最近的写入，在复制延迟窗口内将相关查询固定到主库
+// 记录只保存在本进程内存中，只对同一实例上的后续查询生效
+type StickyTracker struct {
+	window    time.Duration
+	mu        sync.Mutex
+	writes    map[string]time.Time
+	lastSweep time.Time
+}
+
+// NewStickyTracker 创建写入跟踪器，window 为0时不固定主库
+func NewStickyTracker(window time.Duration) *StickyTracker {
+	return &StickyTracker{
+		window: window,
+		writes: make(map[string]time.Time),
+	}
+}
+
+// MarkWrite 记录一次写入，keys 通常为用户ID和订单ID
+func (t *StickyTracker) MarkWrite(keys ...string) {
+	if t == nil || t.window <= 0 {
+		return
+	}
+
+	now := time.Now()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	// 每个窗口最多清理一次过期记录，避免占用内存持续增长，又不让每次写入都遍历全部记录
+	if now.Sub(t.lastSweep) >= t.window {
+		for key, at := range t.writes {
+			if now.Sub(at) >= t.window {
+				delete(t.writes, key)
+			}
+		}
+		t.lastSweep = now
+	}
+	for _, key := range keys {
+		if key != "" {
+			t.writes[key] = now
+		}
+	}
+}
+
+// Recent 判断任一 key 是否在复制延迟窗口内发生过写入
+func (t *StickyTracker) Recent(keys ...string) bool {
+	if t == nil || t.window <= 0 {
+		return false
+	}
+
+	now := time.Now()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, key := range keys {
+		at, ok := t.writes[key]
+		if !ok {
+			continue
+		}
+		if now.Sub(at) < t.window {
+			return true
+		}
+		delete(t.writes, key)
+	}
+	return false
+}
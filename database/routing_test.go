@@ -0,0 +1,37 @@
+package database_test
+
+import (
+	"order_api/database"
+	"testing"
+	"time"
+)
+
+func TestStickyTrackerWindow(t *testing.T) {
+	tracker := database.NewStickyTracker(50 * time.Millisecond)
+	tracker.MarkWrite("user:alice", "order:1")
+	if !tracker.Recent("user:alice") || !tracker.Recent("order:2", "order:1") {
+		t.Fatal("Recent = false right after MarkWrite")
+	}
+	if tracker.Recent("user:bob") {
+		t.Fatal("Recent = true for a key that was never written")
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	if tracker.Recent("user:alice") {
+		t.Fatal("Recent = true after the window elapsed")
+	}
+
+	// 过期清理后重新写入的记录仍然生效
+	tracker.MarkWrite("user:bob")
+	if !tracker.Recent("user:bob") {
+		t.Fatal("Recent = false for a write after the sweep")
+	}
+}
+
+func TestStickyTrackerDisabled(t *testing.T) {
+	tracker := database.NewStickyTracker(0)
+	tracker.MarkWrite("user:alice")
+	if tracker.Recent("user:alice") {
+		t.Fatal("Recent = true with a zero window")
+	}
+}
@@ -0,0 +1,111 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"log"
+	"order_api/config"
+	"sync"
+)
+
+// Cluster 按 user_id 哈希分片的数据库集合，未配置分片时只包含一个分片
+type Cluster struct {
+	shards []*Database
+}
+
+// NewCluster 连接全部分片，任一分片连接失败时关闭已建立的连接
+func NewCluster(cfg *config.DatabaseConfig) (*Cluster, error) {
+	cluster := &Cluster{}
+	for i := 0; i < cfg.ShardCount(); i++ {
+		shardCfg := cfg.ShardConfig(i)
+		// 0号分片沿用数据库名，保持与未分片时相同的日志和指标标签
+		name := shardCfg.DBName
+		if i > 0 {
+			name = fmt.Sprintf("%s_shard_%d", shardCfg.DBName, i)
+		}
+		db, err := NewDatabase(&shardCfg, name)
+		if err != nil {
+			cluster.Close()
+			return nil, fmt.Errorf("failed to connect to shard %d: %w", i, err)
+		}
+		cluster.shards = append(cluster.shards, db)
+	}
+	return cluster, nil
+}
+
+// Len 返回分片数量
+func (c *Cluster) Len() int {
+	return len(c.shards)
+}
+
+// Shard 返回第 i 个分片
+func (c *Cluster) Shard(i int) *Database {
+	return c.shards[i]
+}
+
+// Shards 返回全部分片，顺序与分片序号一致
+func (c *Cluster) Shards() []*Database {
+	return c.shards
+}
+
+// ShardForUser 返回用户订单所在的分片
+func (c *Cluster) ShardForUser(userID string) int {
+	h := fnv.New32a()
+	h.Write([]byte(userID))
+	return int(h.Sum32() % uint32(len(c.shards)))
+}
+
+// ShardForOrder 从订单ID中解析所在分片，ID 无效或分片不存在时返回 false
+func (c *Cluster) ShardForOrder(orderID string) (int, bool) {
+	shard, ok := ShardOfID(orderID)
+	if !ok || shard >= len(c.shards) {
+		return 0, false
+	}
+	return shard, true
+}
+
+// FanOut 在全部分片上并发执行 fn，返回第一个错误
+func (c *Cluster) FanOut(ctx context.Context, fn func(ctx context.Context, shard int, db *Database) error) error {
+	if len(c.shards) == 1 {
+		return fn(ctx, 0, c.shards[0])
+	}
+
+	errs := make([]error, len(c.shards))
+	var wg sync.WaitGroup
+	for i, db := range c.shards {
+		wg.Add(1)
+		go func(i int, db *Database) {
+			defer wg.Done()
+			errs[i] = fn(ctx, i, db)
+		}(i, db)
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			return fmt.Errorf("shard %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// SetQueryLogging 在全部分片上调整SQL日志设置
+func (c *Cluster) SetQueryLogging(cfg *config.DatabaseConfig) {
+	for _, db := range c.shards {
+		db.SetQueryLogging(cfg)
+	}
+}
+
+func (c *Cluster) Close() error {
+	var firstErr error
+	for i, db := range c.shards {
+		if err := db.Close(); err != nil {
+			log.Printf("Error closing shard %d connection: %v", i, err)
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	return firstErr
+}
@@ -0,0 +1,104 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"gorm.io/gorm/clause"
+)
+
+// topologyRowID shard_topology 表中唯一一行的主键
+const topologyRowID = 1
+
+// shardTopology shard_topology 表的记录，每个分片保存自己的序号和当时的分片数量
+type shardTopology struct {
+	ID         int `gorm:"primaryKey;autoIncrement:false"`
+	ShardIndex int
+	ShardCount int
+	UpdatedAt  time.Time
+}
+
+func (shardTopology) TableName() string {
+	return "shard_topology"
+}
+
+// CheckTopology 检查分片配置与已有数据的分片布局一致
+// 订单按 user_id 哈希对分片数量取模分布，引入分片前的订单都在0号分片；分片数量或顺序变化后，
+// 已有用户会被路由到其他分片，历史订单从订单列表中消失，因此存在订单时拒绝启动。
+// 没有记录的分片（新建的库或升级前创建的库）无法确认订单按哪种布局写入，多个分片时同样要求还没有订单；
+// 还没有订单时按当前配置更新记录
+func (c *Cluster) CheckTopology(ctx context.Context) error {
+	recorded := make([]*shardTopology, len(c.shards))
+	for i, db := range c.shards {
+		conn := db.Primary().WithContext(ctx)
+		if !conn.Migrator().HasTable(&shardTopology{}) {
+			log.Printf("Shard %d has no shard_topology table, skipping shard layout check until migrations are applied", i)
+			return nil
+		}
+		var row shardTopology
+		err := conn.Limit(1).Find(&row, "id = ?", topologyRowID).Error
+		if err != nil {
+			return fmt.Errorf("failed to read shard topology of shard %d: %w", i, err)
+		}
+		if row.ID != 0 {
+			recorded[i] = &row
+		}
+	}
+
+	var changes []string
+	for i, row := range recorded {
+		switch {
+		case row == nil && len(c.shards) > 1:
+			changes = append(changes, fmt.Sprintf("分片 %d 没有分片布局记录", i))
+		case row != nil && (row.ShardIndex != i || row.ShardCount != len(c.shards)):
+			changes = append(changes, fmt.Sprintf("分片 %d 原为 %d 个分片中的 %d 号分片", i, row.ShardCount, row.ShardIndex))
+		}
+	}
+	if len(changes) > 0 {
+		hasOrders, err := c.hasOrders(ctx)
+		if err != nil {
+			return err
+		}
+		if hasOrders {
+			return fmt.Errorf("分片配置与已有数据不一致（当前为 %d 个分片，%s）：订单按 user_id 哈希分布，改变分片数量或顺序会使用户的历史订单从列表中消失；"+
+				"请恢复原来的分片配置；确认订单已按当前配置分布（迁移数据后，或升级前已按当前配置分片）时，在各分片的 shard_topology 表中写入当前布局", len(c.shards), strings.Join(changes, "，"))
+		}
+	}
+
+	for i, db := range c.shards {
+		row := recorded[i]
+		if row != nil && row.ShardIndex == i && row.ShardCount == len(c.shards) {
+			continue
+		}
+		err := db.Primary().WithContext(ctx).Clauses(clause.OnConflict{UpdateAll: true}).Create(&shardTopology{
+			ID:         topologyRowID,
+			ShardIndex: i,
+			ShardCount: len(c.shards),
+		}).Error
+		if err != nil {
+			return fmt.Errorf("failed to record shard topology of shard %d: %w", i, err)
+		}
+		if row != nil {
+			log.Printf("Shard %d has no orders yet, updated its layout to shard %d of %d", i, i, len(c.shards))
+		}
+	}
+	return nil
+}
+
+// hasOrders 判断任一分片中是否存在订单，包括已软删除的订单
+func (c *Cluster) hasOrders(ctx context.Context) (bool, error) {
+	for i, db := range c.shards {
+		var ids []string
+		err := db.Primary().WithContext(ctx).Table("orders").Limit(1).Pluck("id", &ids).Error
+		if err != nil {
+			return false, fmt.Errorf("failed to check orders on shard %d: %w", i, err)
+		}
+		if len(ids) > 0 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
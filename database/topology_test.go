@@ -0,0 +1,149 @@
+package database_test
+
+import (
+	"context"
+	"order_api/database"
+	"order_api/database/dbtest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// openShards 打开 dir 中指定名称的 SQLite 文件作为分片并执行迁移
+func openShards(t *testing.T, dir string, names ...string) *database.Cluster {
+	t.Helper()
+	paths := make([]string, len(names))
+	for i, name := range names {
+		paths[i] = filepath.Join(dir, name+".db")
+	}
+	return dbtest.Open(t, dbtest.Config(paths...))
+}
+
+func insertOrder(t *testing.T, cluster *database.Cluster, shard int) {
+	t.Helper()
+	err := cluster.Shard(shard).Exec("INSERT INTO orders (id, user_id, status, amount) VALUES (?, ?, 'pending', 0)",
+		database.NewID(shard), "alice").Error
+	if err != nil {
+		t.Fatalf("insert order: %v", err)
+	}
+}
+
+func TestCheckTopologyRejectsShardCountChangeWithOrders(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	single := openShards(t, dir, "a")
+	if err := single.CheckTopology(ctx); err != nil {
+		t.Fatalf("first CheckTopology: %v", err)
+	}
+	insertOrder(t, single, 0)
+	single.Close()
+
+	grown := openShards(t, dir, "a", "b")
+	err := grown.CheckTopology(ctx)
+	if err == nil || !strings.Contains(err.Error(), "分片配置与已有数据不一致") {
+		t.Fatalf("CheckTopology after adding a shard = %v, want layout error", err)
+	}
+	grown.Close()
+
+	// 恢复原来的配置后可以正常启动
+	restored := openShards(t, dir, "a")
+	if err := restored.CheckTopology(ctx); err != nil {
+		t.Fatalf("CheckTopology with the original layout: %v", err)
+	}
+}
+
+func TestCheckTopologyRejectsReorderedShards(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	cluster := openShards(t, dir, "a", "b")
+	if err := cluster.CheckTopology(ctx); err != nil {
+		t.Fatalf("first CheckTopology: %v", err)
+	}
+	insertOrder(t, cluster, 1)
+	cluster.Close()
+
+	swapped := openShards(t, dir, "b", "a")
+	if err := swapped.CheckTopology(ctx); err == nil {
+		t.Fatal("CheckTopology with reordered shards succeeded, want layout error")
+	}
+}
+
+func TestCheckTopologyUpdatesLayoutWithoutOrders(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	if err := openShards(t, dir, "a").CheckTopology(ctx); err != nil {
+		t.Fatalf("first CheckTopology: %v", err)
+	}
+
+	// 还没有订单时可以调整分片，新的布局随之记录
+	grown := openShards(t, dir, "a", "b")
+	if err := grown.CheckTopology(ctx); err != nil {
+		t.Fatalf("CheckTopology after adding a shard to an empty cluster: %v", err)
+	}
+	insertOrder(t, grown, 0)
+	grown.Close()
+
+	if err := openShards(t, dir, "a").CheckTopology(ctx); err == nil {
+		t.Fatal("CheckTopology after removing a shard succeeded, want layout error")
+	}
+}
+
+// writeTopology 按 README 的说明手工写入分片布局
+func writeTopology(t *testing.T, cluster *database.Cluster) {
+	t.Helper()
+	for i := 0; i < cluster.Len(); i++ {
+		err := cluster.Shard(i).Exec("INSERT INTO shard_topology (id, shard_index, shard_count, updated_at) VALUES (1, ?, ?, CURRENT_TIMESTAMP)",
+			i, cluster.Len()).Error
+		if err != nil {
+			t.Fatalf("write topology: %v", err)
+		}
+	}
+}
+
+func TestCheckTopologyRejectsUnrecordedShardsWithOrders(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	// 升级前的单库部署：已有订单，还没有 shard_topology 记录
+	single := openShards(t, dir, "a")
+	insertOrder(t, single, 0)
+	single.Close()
+
+	grown := openShards(t, dir, "a", "b")
+	err := grown.CheckTopology(ctx)
+	if err == nil || !strings.Contains(err.Error(), "没有分片布局记录") {
+		t.Fatalf("CheckTopology after upgrading to two shards = %v, want layout error", err)
+	}
+	var count int64
+	if err := grown.Shard(1).Table("shard_topology").Count(&count).Error; err != nil {
+		t.Fatalf("count shard_topology: %v", err)
+	}
+	if count != 0 {
+		t.Fatalf("shard 1 has %d topology rows after a rejected check, want 0", count)
+	}
+
+	// 确认数据已按当前配置分布后手工写入布局即可启动
+	writeTopology(t, grown)
+	if err := grown.CheckTopology(ctx); err != nil {
+		t.Fatalf("CheckTopology after recording the layout: %v", err)
+	}
+}
+
+func TestCheckTopologyRecordsUnrecordedSingleShard(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	single := openShards(t, dir, "a")
+	insertOrder(t, single, 0)
+	if err := single.CheckTopology(ctx); err != nil {
+		t.Fatalf("CheckTopology on an upgraded single database: %v", err)
+	}
+	single.Close()
+
+	if err := openShards(t, dir, "a", "b").CheckTopology(ctx); err == nil {
+		t.Fatal("CheckTopology after adding a shard succeeded, want layout error")
+	}
+}
@@ -0,0 +1,121 @@
+// Package docs 提供 REST 接口的 OpenAPI 3 规范和交互式文档页面
+//
+// openapi.json 手工维护，修改路由时需要同步更新，order_api openapi check 检查两者是否一致
+package docs
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	swaggerFiles "github.com/swaggo/files"
+)
+
+//go:embed openapi.json
+var spec []byte
+
+//go:embed index.html
+var index []byte
+
+// Spec 返回 OpenAPI 规范的 JSON 内容
+func Spec() []byte {
+	return spec
+}
+
+// Index 返回文档首页，页面从 /openapi.json 加载规范
+func Index() []byte {
+	return index
+}
+
+// Assets 返回文档页面使用的 Swagger UI 静态文件，随程序一起编译，不依赖外部 CDN
+func Assets() http.FileSystem {
+	return swaggerFiles.HTTP
+}
+
+// specPaths 只解析规范中的路径和方法
+type specPaths struct {
+	Paths map[string]map[string]any `json:"paths"`
+}
+
+// operationMethods OpenAPI 路径项中表示操作的字段，其余字段如 parameters 不是操作
+var operationMethods = map[string]bool{
+	"get": true, "put": true, "post": true, "delete": true,
+	"options": true, "head": true, "patch": true, "trace": true,
+}
+
+// Operations 返回规范中的全部操作，格式为 "GET /api/v1/orders/{id}"
+func Operations() ([]string, error) {
+	var doc specPaths
+	if err := json.Unmarshal(spec, &doc); err != nil {
+		return nil, fmt.Errorf("解析OpenAPI规范失败: %w", err)
+	}
+	var ops []string
+	for path, item := range doc.Paths {
+		for method := range item {
+			if operationMethods[method] {
+				ops = append(ops, strings.ToUpper(method)+" "+path)
+			}
+		}
+	}
+	sort.Strings(ops)
+	return ops, nil
+}
+
+// CheckRoutes 比较路由与规范，列出未写入规范的路由和规范中不存在的路由
+// 通配路由（如 /docs/*filepath 静态文件）不在规范中描述，不参与比较
+func CheckRoutes(routes gin.RoutesInfo) error {
+	ops, err := Operations()
+	if err != nil {
+		return err
+	}
+	documented := make(map[string]bool, len(ops))
+	for _, op := range ops {
+		documented[op] = true
+	}
+
+	registered := make(map[string]bool, len(routes))
+	var undocumented, missing []string
+	for _, route := range routes {
+		if strings.Contains(route.Path, "*") {
+			continue
+		}
+		op := route.Method + " " + openAPIPath(route.Path)
+		registered[op] = true
+		if !documented[op] {
+			undocumented = append(undocumented, op)
+		}
+	}
+	for _, op := range ops {
+		if !registered[op] {
+			missing = append(missing, op)
+		}
+	}
+	if len(undocumented) == 0 && len(missing) == 0 {
+		return nil
+	}
+
+	sort.Strings(undocumented)
+	var problems []string
+	if len(undocumented) > 0 {
+		problems = append(problems, "以下路由未写入规范: "+strings.Join(undocumented, ", "))
+	}
+	if len(missing) > 0 {
+		problems = append(problems, "规范中以下接口没有对应路由: "+strings.Join(missing, ", "))
+	}
+	return fmt.Errorf("OpenAPI规范与路由不一致: %s", strings.Join(problems, "；"))
+}
+
+// openAPIPath 将 gin 的路径参数 :id 转换为 OpenAPI 的 {id}
+func openAPIPath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if name, ok := strings.CutPrefix(segment, ":"); ok {
+			segments[i] = "{" + name + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
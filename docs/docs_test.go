@@ -0,0 +1,43 @@
+package docs_test
+
+import (
+	"order_api/docs"
+	"order_api/router"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// TestCheckRoutes 服务实际注册的路由必须与 openapi.json 一致
+func TestCheckRoutes(t *testing.T) {
+	if err := docs.CheckRoutes(router.Routes()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCheckRoutesReportsDrift(t *testing.T) {
+	routes := router.Routes()
+	var pruned gin.RoutesInfo
+	for _, route := range routes {
+		if route.Method == "GET" && route.Path == "/api/v1/orders/:id" {
+			continue
+		}
+		pruned = append(pruned, route)
+	}
+	pruned = append(pruned, gin.RouteInfo{Method: "POST", Path: "/api/v1/orders/:id/cancel"})
+
+	err := docs.CheckRoutes(pruned)
+	if err == nil {
+		t.Fatal("expected drift to be reported")
+	}
+	for _, want := range []string{"POST /api/v1/orders/{id}/cancel", "GET /api/v1/orders/{id}"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not mention %s", err, want)
+		}
+	}
+}
@@ -18,6 +18,11 @@ var (
 	ErrCacheError        = errors.New("cache error")
 	ErrUnauthorized      = errors.New("unauthorized access")
 	ErrForbidden         = errors.New("forbidden")
+	ErrEventNotFound     = errors.New("outbox event not found")
+	ErrCrossShard        = errors.New("transaction cannot span multiple shards")
+	ErrUserNotFound      = errors.New("user not found")
+	ErrUserExists        = errors.New("user already exists")
+	ErrInvalidRole       = errors.New("invalid role")
 )
 
 type AppError struct {
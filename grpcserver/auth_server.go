@@ -0,0 +1,21 @@
+package grpcserver
+
+import (
+	"context"
+	"order_api/app/auth"
+	orderapiv1 "order_api/proto/orderapi/v1"
+)
+
+// authServer 登录 gRPC 接口
+type authServer struct {
+	orderapiv1.UnimplementedAuthServiceServer
+	authService *auth.AuthService
+}
+
+func (s *authServer) Login(ctx context.Context, req *orderapiv1.LoginRequest) (*orderapiv1.LoginResponse, error) {
+	token, err := s.authService.Login(ctx, req.GetUsername(), req.GetPassword())
+	if err != nil {
+		return nil, err
+	}
+	return &orderapiv1.LoginResponse{Token: token, Type: "Bearer"}, nil
+}
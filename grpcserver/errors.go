@@ -0,0 +1,55 @@
+package grpcserver
+
+import (
+	"context"
+	"log"
+	"order_api/app/auth"
+	"order_api/errors"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// errorCodes 业务错误对应的 gRPC 状态码，与 REST 接口返回的 HTTP 状态码含义一致
+var errorCodes = []struct {
+	err  error
+	code codes.Code
+}{
+	{errors.ErrOrderNotFound, codes.NotFound},
+	{errors.ErrUserNotFound, codes.NotFound},
+	{errors.ErrEventNotFound, codes.NotFound},
+	{errors.ErrUnauthorized, codes.PermissionDenied},
+	{errors.ErrForbidden, codes.PermissionDenied},
+	{errors.ErrInvalidOrderStatus, codes.InvalidArgument},
+	{errors.ErrInvalidOrderID, codes.InvalidArgument},
+	{errors.ErrEmptyOrder, codes.InvalidArgument},
+	{errors.ErrInvalidUserID, codes.InvalidArgument},
+	{errors.ErrInvalidAmount, codes.InvalidArgument},
+	{errors.ErrInvalidQuantity, codes.InvalidArgument},
+	{errors.ErrInvalidPrice, codes.InvalidArgument},
+	{errors.ErrInvalidRole, codes.InvalidArgument},
+	{errors.ErrUserExists, codes.AlreadyExists},
+	{errors.ErrCrossShard, codes.FailedPrecondition},
+	{auth.ErrInvalidCredentials, codes.Unauthenticated},
+	{auth.ErrInvalidToken, codes.Unauthenticated},
+	{auth.ErrExpiredToken, codes.Unauthenticated},
+	{context.DeadlineExceeded, codes.DeadlineExceeded},
+	{context.Canceled, codes.Canceled},
+}
+
+// toStatus 将业务错误转换为 gRPC 状态，未知错误记录日志并返回 Internal，不向调用方暴露内部细节
+func toStatus(method string, err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	for _, e := range errorCodes {
+		if errors.Is(err, e.err) {
+			return status.Error(e.code, err.Error())
+		}
+	}
+	log.Printf("gRPC %s failed: %v", method, err)
+	return status.Error(codes.Internal, "服务器内部错误")
+}
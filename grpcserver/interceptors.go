@@ -0,0 +1,119 @@
+package grpcserver
+
+import (
+	"context"
+	"log"
+	"order_api/app/auth"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// publicMethods 不需要认证的方法，健康检查和反射服务按服务名前缀匹配
+var publicMethods = []string{
+	"/orderapi.v1.AuthService/Login",
+	"/grpc.health.v1.Health/",
+	"/grpc.reflection.",
+}
+
+func isPublic(method string) bool {
+	for _, prefix := range publicMethods {
+		if strings.HasPrefix(method, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// claimsKey 认证后的用户身份在 context 中的键
+type claimsKey struct{}
+
+// claimsFrom 返回认证后的用户身份
+func claimsFrom(ctx context.Context) (*auth.Claims, bool) {
+	claims, ok := ctx.Value(claimsKey{}).(*auth.Claims)
+	return claims, ok
+}
+
+// authenticate 与 middleware.Auth 相同：优先使用 x-api-key，否则验证 authorization 中的 Bearer 令牌
+func authenticate(ctx context.Context, authService *auth.AuthService) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if keys := md.Get("x-api-key"); len(keys) > 0 && keys[0] != "" {
+		claims, err := authService.ValidateAPIKey(ctx, keys[0])
+		if err != nil {
+			return nil, status.Error(codes.Unauthenticated, "无效或已过期的API密钥")
+		}
+		return context.WithValue(ctx, claimsKey{}, claims), nil
+	}
+
+	values := md.Get("authorization")
+	if len(values) == 0 {
+		return nil, status.Error(codes.Unauthenticated, "缺少authorization元数据")
+	}
+	token, ok := strings.CutPrefix(values[0], "Bearer ")
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "无效的authorization格式")
+	}
+	claims, err := authService.ValidateToken(token)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, "无效或已过期的令牌")
+	}
+	return context.WithValue(ctx, claimsKey{}, claims), nil
+}
+
+// unaryInterceptor 恢复 panic、认证并记录请求摘要，日志格式与 HTTP 请求日志一致
+func unaryInterceptor(authService *auth.AuthService) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		start := time.Now()
+		defer func() {
+			if r := recover(); r != nil {
+				log.Printf("gRPC %s panic: %v\n%s", info.FullMethod, r, debug.Stack())
+				err = status.Error(codes.Internal, "服务器内部错误")
+			}
+			log.Printf("[INFO] gRPC %s %s %s", info.FullMethod, status.Code(err), time.Since(start))
+		}()
+
+		if !isPublic(info.FullMethod) {
+			if ctx, err = authenticate(ctx, authService); err != nil {
+				return nil, err
+			}
+		}
+		resp, err = handler(ctx, req)
+		return resp, toStatus(info.FullMethod, err)
+	}
+}
+
+// authStream 携带认证后 context 的流
+type authStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authStream) Context() context.Context {
+	return s.ctx
+}
+
+// streamInterceptor 流式方法的 panic 恢复和认证，目前只有健康检查的 Watch 和反射服务使用
+func streamInterceptor(authService *auth.AuthService) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Printf("gRPC %s panic: %v\n%s", info.FullMethod, r, debug.Stack())
+				err = status.Error(codes.Internal, "服务器内部错误")
+			}
+		}()
+
+		if !isPublic(info.FullMethod) {
+			ctx, err := authenticate(ss.Context(), authService)
+			if err != nil {
+				return err
+			}
+			ss = &authStream{ServerStream: ss, ctx: ctx}
+		}
+		return toStatus(info.FullMethod, handler(srv, ss))
+	}
+}
@@ -0,0 +1,124 @@
+package grpcserver
+
+import (
+	"context"
+	"order_api/model"
+	orderapiv1 "order_api/proto/orderapi/v1"
+	"order_api/service"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// orderServer 订单 gRPC 接口，业务逻辑与 REST 接口共用 OrderService
+type orderServer struct {
+	orderapiv1.UnimplementedOrderServiceServer
+	orderService *service.OrderService
+}
+
+// userID 返回认证拦截器写入的用户ID
+func userID(ctx context.Context) (string, error) {
+	claims, ok := claimsFrom(ctx)
+	if !ok || claims.UserID == "" {
+		return "", status.Error(codes.Unauthenticated, "未认证")
+	}
+	return claims.UserID, nil
+}
+
+func (s *orderServer) CreateOrder(ctx context.Context, req *orderapiv1.CreateOrderRequest) (*orderapiv1.Order, error) {
+	uid, err := userID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	order := &model.Order{UserID: uid, Status: model.StatusPending}
+	for _, item := range req.GetItems() {
+		order.Items = append(order.Items, model.OrderItem{
+			ProductID: item.GetProductId(),
+			Quantity:  int(item.GetQuantity()),
+			Price:     item.GetPrice(),
+		})
+	}
+	// 请求内容的错误返回 InvalidArgument，而不是作为服务端错误
+	if err := order.Validate(); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err := s.orderService.CreateOrder(ctx, order); err != nil {
+		return nil, err
+	}
+	return toOrder(order), nil
+}
+
+func (s *orderServer) GetOrder(ctx context.Context, req *orderapiv1.GetOrderRequest) (*orderapiv1.Order, error) {
+	uid, err := userID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	order, err := s.orderService.GetOrder(ctx, req.GetId(), uid)
+	if err != nil {
+		return nil, err
+	}
+	return toOrder(order), nil
+}
+
+func (s *orderServer) ListOrders(ctx context.Context, _ *orderapiv1.ListOrdersRequest) (*orderapiv1.ListOrdersResponse, error) {
+	uid, err := userID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	orders, err := s.orderService.ListOrders(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+	resp := &orderapiv1.ListOrdersResponse{Orders: make([]*orderapiv1.Order, 0, len(orders))}
+	for i := range orders {
+		resp.Orders = append(resp.Orders, toOrder(&orders[i]))
+	}
+	return resp, nil
+}
+
+func (s *orderServer) UpdateOrderStatus(ctx context.Context, req *orderapiv1.UpdateOrderStatusRequest) (*emptypb.Empty, error) {
+	uid, err := userID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.orderService.UpdateOrderStatus(ctx, req.GetId(), uid, req.GetStatus()); err != nil {
+		return nil, err
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (s *orderServer) DeleteOrder(ctx context.Context, req *orderapiv1.DeleteOrderRequest) (*emptypb.Empty, error) {
+	uid, err := userID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.orderService.DeleteOrder(ctx, req.GetId(), uid); err != nil {
+		return nil, err
+	}
+	return &emptypb.Empty{}, nil
+}
+
+// toOrder 将订单模型转换为 protobuf 消息
+func toOrder(order *model.Order) *orderapiv1.Order {
+	pb := &orderapiv1.Order{
+		Id:        order.ID,
+		Number:    order.Number,
+		UserId:    order.UserID,
+		Status:    order.Status,
+		Amount:    order.Amount,
+		Items:     make([]*orderapiv1.OrderItem, 0, len(order.Items)),
+		CreatedAt: timestamppb.New(order.CreatedAt),
+		UpdatedAt: timestamppb.New(order.UpdatedAt),
+	}
+	for _, item := range order.Items {
+		pb.Items = append(pb.Items, &orderapiv1.OrderItem{
+			Id:        item.ID,
+			ProductId: item.ProductID,
+			Quantity:  int32(item.Quantity),
+			Price:     item.Price,
+		})
+	}
+	return pb
+}
@@ -0,0 +1,99 @@
+// Package grpcserver 提供与 REST 接口并行的 gRPC 接口，在独立端口上监听
+package grpcserver
+
+import (
+	"context"
+	"log"
+	"net"
+	"order_api/app/auth"
+	"order_api/config"
+	orderapiv1 "order_api/proto/orderapi/v1"
+	"order_api/service"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+)
+
+// Server gRPC 服务，包含订单、登录、标准健康检查和可选的反射服务
+type Server struct {
+	port   string
+	server *grpc.Server
+	health *health.Server
+}
+
+// New 创建 gRPC 服务，creds 为空时使用明文连接
+func New(cfg *config.GRPCConfig, orderService *service.OrderService, authService *auth.AuthService, creds credentials.TransportCredentials) *Server {
+	opts := []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(unaryInterceptor(authService)),
+		grpc.ChainStreamInterceptor(streamInterceptor(authService)),
+	}
+	if creds != nil {
+		opts = append(opts, grpc.Creds(creds))
+	}
+
+	s := &Server{
+		port:   cfg.Port,
+		server: grpc.NewServer(opts...),
+		health: health.NewServer(),
+	}
+	orderapiv1.RegisterOrderServiceServer(s.server, &orderServer{orderService: orderService})
+	orderapiv1.RegisterAuthServiceServer(s.server, &authServer{authService: authService})
+	healthpb.RegisterHealthServer(s.server, s.health)
+	if cfg.Reflection {
+		reflection.Register(s.server)
+	}
+	return s
+}
+
+// Serve 监听端口并处理请求，直到 Shutdown 被调用
+func (s *Server) Serve() error {
+	lis, err := net.Listen("tcp", ":"+s.port)
+	if err != nil {
+		return err
+	}
+	log.Printf("gRPC server starting on port %s", s.port)
+	return s.server.Serve(lis)
+}
+
+// WatchReadiness 按 interval 执行就绪检查并更新标准健康检查服务的状态，直到 ctx 结束
+func (s *Server) WatchReadiness(ctx context.Context, ready func(ctx context.Context) bool, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		status := healthpb.HealthCheckResponse_NOT_SERVING
+		if ready(ctx) {
+			status = healthpb.HealthCheckResponse_SERVING
+		}
+		// 空服务名表示整个服务器的状态
+		for _, name := range []string{"", orderapiv1.OrderService_ServiceDesc.ServiceName, orderapiv1.AuthService_ServiceDesc.ServiceName} {
+			s.health.SetServingStatus(name, status)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Shutdown 将健康状态设为 NOT_SERVING，等待处理中的调用完成，ctx 结束时强制关闭连接
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.health.Shutdown()
+	done := make(chan struct{})
+	go func() {
+		s.server.GracefulStop()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		s.server.Stop()
+		return ctx.Err()
+	}
+}
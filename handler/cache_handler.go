@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"order_api/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CacheHandler 缓存运维管理接口
+type CacheHandler struct {
+	cacheService *service.CacheService
+	checker      *service.ConsistencyChecker
+}
+
+func NewCacheHandler(cacheService *service.CacheService, checker *service.ConsistencyChecker) *CacheHandler {
+	return &CacheHandler{
+		cacheService: cacheService,
+		checker:      checker,
+	}
+}
+
+// InspectOrder 查看订单缓存状态
+func (h *CacheHandler) InspectOrder(c *gin.Context) {
+	info, err := h.cacheService.InspectOrder(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		ServerError(c, err)
+		return
+	}
+	Success(c, info)
+}
+
+// EvictOrder 删除单个订单缓存
+func (h *CacheHandler) EvictOrder(c *gin.Context) {
+	if err := h.cacheService.EvictOrder(c.Request.Context(), c.Param("id")); err != nil {
+		ServerError(c, err)
+		return
+	}
+	Success(c, gin.H{"message": "订单缓存已删除"})
+}
+
+// EvictUser 删除用户的全部订单缓存
+func (h *CacheHandler) EvictUser(c *gin.Context) {
+	count, err := h.cacheService.EvictUser(c.Request.Context(), c.Param("user_id"))
+	if err != nil {
+		ServerError(c, err)
+		return
+	}
+	Success(c, gin.H{"evicted": count})
+}
+
+// FlushLocal 清空所有节点的本地缓存
+func (h *CacheHandler) FlushLocal(c *gin.Context) {
+	if err := h.cacheService.FlushLocal(c.Request.Context()); err != nil {
+		ServerError(c, err)
+		return
+	}
+	Success(c, gin.H{"message": "本地缓存清理已广播"})
+}
+
+// Warmup 手动触发缓存预热
+func (h *CacheHandler) Warmup(c *gin.Context) {
+	result, err := h.cacheService.Warmup(c.Request.Context())
+	if err != nil {
+		ServerError(c, err)
+		return
+	}
+	Success(c, result)
+}
+
+// ConsistencyReport 查看最近一次缓存一致性巡检结果
+func (h *CacheHandler) ConsistencyReport(c *gin.Context) {
+	report := h.checker.LastReport()
+	if report == nil {
+		NotFound(c, "尚未执行一致性巡检")
+		return
+	}
+	Success(c, report)
+}
+
+// CheckConsistency 立即执行一次缓存一致性巡检
+func (h *CacheHandler) CheckConsistency(c *gin.Context) {
+	Success(c, h.checker.Check(c.Request.Context()))
+}
@@ -0,0 +1,81 @@
+package handler
+
+import (
+	"database/sql"
+	"order_api/database"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DBStatsResponse 连接池统计信息
+type DBStatsResponse struct {
+	MaxOpenConnections int    `json:"max_open_connections"`
+	OpenConnections    int    `json:"open_connections"`
+	InUse              int    `json:"in_use"`
+	Idle               int    `json:"idle"`
+	WaitCount          int64  `json:"wait_count"`
+	WaitDuration       string `json:"wait_duration"`
+	MaxIdleClosed      int64  `json:"max_idle_closed"`
+	MaxIdleTimeClosed  int64  `json:"max_idle_time_closed"`
+	MaxLifetimeClosed  int64  `json:"max_lifetime_closed"`
+
+	Replicas []DBStatsResponse `json:"replicas,omitempty"` // 只读副本连接池，仅主库统计包含该字段
+	Shards   []DBStatsResponse `json:"shards,omitempty"`   // 附加分片的连接池，仅0号分片统计包含该字段
+}
+
+// DiagnosticsHandler 运行时诊断接口
+type DiagnosticsHandler struct {
+	cluster *database.Cluster
+}
+
+func NewDiagnosticsHandler(cluster *database.Cluster) *DiagnosticsHandler {
+	return &DiagnosticsHandler{
+		cluster: cluster,
+	}
+}
+
+// DBStats 查看数据库连接池统计信息，顶层为0号分片
+func (h *DiagnosticsHandler) DBStats(c *gin.Context) {
+	resp, err := shardStats(h.cluster.Shard(0))
+	if err != nil {
+		ServerError(c, err)
+		return
+	}
+	for _, shard := range h.cluster.Shards()[1:] {
+		stats, err := shardStats(shard)
+		if err != nil {
+			ServerError(c, err)
+			return
+		}
+		resp.Shards = append(resp.Shards, stats)
+	}
+	Success(c, resp)
+}
+
+// shardStats 返回分片主库及其只读副本的连接池统计
+func shardStats(db *database.Database) (DBStatsResponse, error) {
+	stats, err := db.Stats()
+	if err != nil {
+		return DBStatsResponse{}, err
+	}
+
+	resp := newDBStatsResponse(stats)
+	for _, replica := range db.Replicas() {
+		resp.Replicas = append(resp.Replicas, newDBStatsResponse(replica.Stats()))
+	}
+	return resp, nil
+}
+
+func newDBStatsResponse(stats sql.DBStats) DBStatsResponse {
+	return DBStatsResponse{
+		MaxOpenConnections: stats.MaxOpenConnections,
+		OpenConnections:    stats.OpenConnections,
+		InUse:              stats.InUse,
+		Idle:               stats.Idle,
+		WaitCount:          stats.WaitCount,
+		WaitDuration:       stats.WaitDuration.String(),
+		MaxIdleClosed:      stats.MaxIdleClosed,
+		MaxIdleTimeClosed:  stats.MaxIdleTimeClosed,
+		MaxLifetimeClosed:  stats.MaxLifetimeClosed,
+	}
+}
@@ -0,0 +1,35 @@
+package handler
+
+import (
+	"net/http"
+	"order_api/docs"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DocsHandler OpenAPI 规范和交互式文档接口，不需要认证
+type DocsHandler struct {
+	assets http.Handler
+}
+
+func NewDocsHandler() *DocsHandler {
+	return &DocsHandler{
+		assets: http.FileServer(docs.Assets()),
+	}
+}
+
+// OpenAPI 返回 OpenAPI 3 规范
+func (h *DocsHandler) OpenAPI(c *gin.Context) {
+	c.Data(http.StatusOK, "application/json; charset=utf-8", docs.Spec())
+}
+
+// UI 返回交互式文档页面及其静态文件，路由为 /docs/*filepath
+func (h *DocsHandler) UI(c *gin.Context) {
+	path := c.Param("filepath")
+	if path == "/" || path == "/index.html" {
+		c.Data(http.StatusOK, "text/html; charset=utf-8", docs.Index())
+		return
+	}
+	c.Request.URL.Path = path
+	h.assets.ServeHTTP(c.Writer, c.Request)
+}
@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"net/http"
+	"order_api/app/auth"
+	"order_api/health"
+	"order_api/middleware"
+	"order_api/model"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// HealthHandler 存活和就绪检查接口，供负载均衡和 Kubernetes 探针调用，不使用统一响应格式
+type HealthHandler struct {
+	health      *health.Health
+	authService *auth.AuthService
+}
+
+func NewHealthHandler(health *health.Health, authService *auth.AuthService) *HealthHandler {
+	return &HealthHandler{
+		health:      health,
+		authService: authService,
+	}
+}
+
+// Livez 存活检查，只要进程能处理请求就返回成功，不检查外部依赖，避免依赖故障时实例被反复重启
+func (h *HealthHandler) Livez(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": health.StatusOK})
+}
+
+// Readyz 就绪检查，依赖不可用或正在关闭时返回 503
+// 携带管理员令牌时返回每项检查的详情，匿名调用只返回总体状态，避免暴露内部拓扑
+func (h *HealthHandler) Readyz(c *gin.Context) {
+	report := h.health.Ready(c.Request.Context())
+
+	status := http.StatusOK
+	if !report.Ready() {
+		status = http.StatusServiceUnavailable
+	}
+	if h.isAdmin(c) {
+		c.JSON(status, report)
+		return
+	}
+	c.JSON(status, gin.H{"status": report.Status})
+}
+
+// isAdmin 判断请求是否携带有效的管理员令牌或 API 密钥，缺失或无效时按匿名请求处理
+func (h *HealthHandler) isAdmin(c *gin.Context) bool {
+	if key := c.GetHeader(middleware.APIKeyHeader); key != "" {
+		claims, err := h.authService.ValidateAPIKey(c.Request.Context(), key)
+		return err == nil && claims.Role == model.RoleAdmin
+	}
+	token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+	if !ok {
+		return false
+	}
+	claims, err := h.authService.ValidateToken(token)
+	return err == nil && claims.Role == model.RoleAdmin
+}
@@ -125,3 +125,18 @@ func (h *OrderHandler) DeleteOrder(c *gin.Context) {
 
 	Success(c, gin.H{"message": "订单删除成功"})
 }
+
+// RestoreOrder 恢复已删除的订单（管理员）
+func (h *OrderHandler) RestoreOrder(c *gin.Context) {
+	order, err := h.orderService.RestoreOrder(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		if errors.Is(err, errors.ErrOrderNotFound) {
+			NotFound(c, "已删除的订单不存在")
+			return
+		}
+		ServerError(c, err)
+		return
+	}
+
+	Success(c, order)
+}
@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"order_api/errors"
+	"order_api/service"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// OutboxHandler 发件箱死信管理接口
+type OutboxHandler struct {
+	relay *service.OutboxRelay
+}
+
+func NewOutboxHandler(relay *service.OutboxRelay) *OutboxHandler {
+	return &OutboxHandler{
+		relay: relay,
+	}
+}
+
+// DeadLetters 查看死信事件
+func (h *OutboxHandler) DeadLetters(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	events, err := h.relay.DeadLetters(c.Request.Context(), limit)
+	if err != nil {
+		ServerError(c, err)
+		return
+	}
+	Success(c, events)
+}
+
+// Retry 重新投递死信事件，事件ID只在分片内唯一，通过 shard 参数指定分片，默认为0号分片
+func (h *OutboxHandler) Retry(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		ValidationError(c, []string{"无效的事件ID"})
+		return
+	}
+	shard, err := strconv.Atoi(c.DefaultQuery("shard", "0"))
+	if err != nil {
+		ValidationError(c, []string{"无效的分片序号"})
+		return
+	}
+
+	if err := h.relay.Retry(c.Request.Context(), shard, id); err != nil {
+		if errors.Is(err, errors.ErrEventNotFound) {
+			NotFound(c, "死信事件不存在")
+			return
+		}
+		ServerError(c, err)
+		return
+	}
+	Success(c, gin.H{"message": "事件已重新加入投递队列"})
+}
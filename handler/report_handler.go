@@ -0,0 +1,65 @@
+package handler
+
+import (
+	"order_api/service"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// defaultReportDays 未指定时间范围时统计最近的天数
+const defaultReportDays = 30
+
+// ReportHandler 管理端跨分片报表接口
+type ReportHandler struct {
+	reportService *service.ReportService
+}
+
+func NewReportHandler(reportService *service.ReportService) *ReportHandler {
+	return &ReportHandler{
+		reportService: reportService,
+	}
+}
+
+// Orders 按状态统计订单，from 和 to 支持 2006-01-02 或 RFC3339 格式，默认为最近30天
+func (h *ReportHandler) Orders(c *gin.Context) {
+	to := time.Now()
+	from := to.AddDate(0, 0, -defaultReportDays)
+
+	var errs []string
+	if value := c.Query("from"); value != "" {
+		t, err := parseReportTime(value)
+		if err != nil {
+			errs = append(errs, "无效的开始时间")
+		}
+		from = t
+	}
+	if value := c.Query("to"); value != "" {
+		t, err := parseReportTime(value)
+		if err != nil {
+			errs = append(errs, "无效的结束时间")
+		}
+		to = t
+	}
+	if len(errs) == 0 && !from.Before(to) {
+		errs = append(errs, "开始时间必须早于结束时间")
+	}
+	if len(errs) > 0 {
+		ValidationError(c, errs)
+		return
+	}
+
+	report, err := h.reportService.OrderReport(c.Request.Context(), from, to)
+	if err != nil {
+		ServerError(c, err)
+		return
+	}
+	Success(c, report)
+}
+
+func parseReportTime(value string) (time.Time, error) {
+	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
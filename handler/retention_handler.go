@@ -0,0 +1,23 @@
+package handler
+
+import (
+	"order_api/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RetentionHandler 订单保留与归档任务管理接口
+type RetentionHandler struct {
+	job *service.RetentionJob
+}
+
+func NewRetentionHandler(job *service.RetentionJob) *RetentionHandler {
+	return &RetentionHandler{
+		job: job,
+	}
+}
+
+// Run 立即执行一次保留任务
+func (h *RetentionHandler) Run(c *gin.Context) {
+	Success(c, h.job.RunOnce(c.Request.Context()))
+}
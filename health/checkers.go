@@ -0,0 +1,83 @@
+package health
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"order_api/database"
+	"time"
+)
+
+// funcChecker 以函数实现的检查
+type funcChecker struct {
+	name string
+	fn   func(ctx context.Context) error
+}
+
+func (c funcChecker) Name() string                    { return c.name }
+func (c funcChecker) Check(ctx context.Context) error { return c.fn(ctx) }
+
+// CheckFunc 将函数包装为 Checker
+func CheckFunc(name string, fn func(ctx context.Context) error) Checker {
+	return funcChecker{name: name, fn: fn}
+}
+
+// Pinger 可以检查连通性的依赖，如缓存后端
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// Ping 检查依赖的连通性
+func Ping(name string, pinger Pinger) Checker {
+	return CheckFunc(name, pinger.Ping)
+}
+
+// Database 检查数据库连接
+func Database(name string, db *sql.DB) Checker {
+	return CheckFunc(name, db.PingContext)
+}
+
+// Migrations 检查数据库的迁移版本与程序内置的迁移一致，存在未应用或失败的迁移时失败
+func Migrations(name string, migrator *database.Migrator) Checker {
+	return CheckFunc(name, func(ctx context.Context) error {
+		status, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		var current int64
+		pending := 0
+		for _, s := range status {
+			if s.Dirty {
+				return fmt.Errorf("migration %d is dirty", s.Version)
+			}
+			if s.Applied {
+				current = s.Version
+			} else {
+				pending++
+			}
+		}
+		if pending > 0 {
+			return fmt.Errorf("schema version %d, %d pending migrations up to %d", current, pending, migrator.Latest())
+		}
+		return nil
+	})
+}
+
+// OutboxBacklog 待投递事件的积压情况
+type OutboxBacklog interface {
+	OldestReadyAt(ctx context.Context) (time.Time, bool, error)
+}
+
+// OutboxLag 检查最早的待投递事件等待时间不超过 maxLag，投递器停止或下游长时间不可用时失败
+func OutboxLag(backlog OutboxBacklog, maxLag time.Duration) Checker {
+	return CheckFunc("outbox_lag", func(ctx context.Context) error {
+		oldest, found, err := backlog.OldestReadyAt(ctx)
+		if err != nil {
+			return err
+		}
+		if lag := time.Since(oldest); found && lag > maxLag {
+			return fmt.Errorf("oldest pending event has waited %s, limit %s", lag.Truncate(time.Second), maxLag)
+		}
+		return nil
+	})
+}
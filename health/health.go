@@ -0,0 +1,137 @@
+// Package health 提供存活和就绪检查
+package health
+
+import (
+	"context"
+	"order_api/config"
+	"order_api/metrics"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// 检查状态
+const (
+	StatusOK          = "ok"          // 全部依赖可用
+	StatusFailed      = "failed"      // 单项检查失败
+	StatusUnavailable = "unavailable" // 存在失败的检查，不应接收流量
+	StatusDraining    = "draining"    // 正在优雅关闭，不应接收新流量
+)
+
+// Checker 依赖检查，返回 nil 表示依赖可用
+type Checker interface {
+	Name() string
+	Check(ctx context.Context) error
+}
+
+// Result 单项检查的结果
+type Result struct {
+	Name      string    `json:"name"`
+	Status    string    `json:"status"`
+	Error     string    `json:"error,omitempty"`
+	Duration  string    `json:"duration"`
+	CheckedAt time.Time `json:"checked_at"`
+	Cached    bool      `json:"cached"` // 结果来自缓存，未重新访问依赖
+}
+
+// Report 就绪检查报告
+type Report struct {
+	Status string   `json:"status"`
+	Checks []Result `json:"checks"`
+}
+
+// Ready 报告是否可以接收流量
+func (r *Report) Ready() bool {
+	return r.Status == StatusOK
+}
+
+// check 已注册的检查及其最近一次结果，同一检查同时只执行一次
+type check struct {
+	checker Checker
+	mu      sync.Mutex
+	last    *Result
+}
+
+// Health 就绪检查，每项检查有独立的超时时间，结果在缓存时间内复用
+type Health struct {
+	timeout  time.Duration
+	cacheTTL time.Duration
+	checks   []*check
+	draining atomic.Bool
+}
+
+func New(cfg *config.HealthConfig) *Health {
+	return &Health{
+		timeout:  cfg.GetTimeout(),
+		cacheTTL: cfg.GetCacheTTL(),
+	}
+}
+
+// Register 注册依赖检查，需在开始接收请求前调用
+func (h *Health) Register(checkers ...Checker) {
+	for _, checker := range checkers {
+		h.checks = append(h.checks, &check{checker: checker})
+	}
+}
+
+// SetDraining 标记服务正在关闭，之后就绪检查始终失败，负载均衡据此停止转发新请求
+func (h *Health) SetDraining() {
+	h.draining.Store(true)
+}
+
+// Ready 并发执行全部检查
+func (h *Health) Ready(ctx context.Context) *Report {
+	report := &Report{Status: StatusOK, Checks: make([]Result, len(h.checks))}
+
+	var wg sync.WaitGroup
+	for i, c := range h.checks {
+		wg.Add(1)
+		go func(i int, c *check) {
+			defer wg.Done()
+			report.Checks[i] = h.run(ctx, c)
+		}(i, c)
+	}
+	wg.Wait()
+
+	for _, result := range report.Checks {
+		if result.Status != StatusOK {
+			report.Status = StatusUnavailable
+		}
+	}
+	if h.draining.Load() {
+		report.Status = StatusDraining
+	}
+	return report
+}
+
+// run 执行单项检查，缓存时间内直接返回上次的结果
+func (h *Health) run(ctx context.Context, c *check) Result {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.last != nil && time.Since(c.last.CheckedAt) < h.cacheTTL {
+		result := *c.last
+		result.Cached = true
+		return result
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, h.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := c.checker.Check(ctx)
+	result := Result{
+		Name:      c.checker.Name(),
+		Status:    StatusOK,
+		Duration:  time.Since(start).String(),
+		CheckedAt: start,
+	}
+	if err != nil {
+		result.Status = StatusFailed
+		result.Error = err.Error()
+	}
+	metrics.SetHealthCheck(result.Name, err == nil)
+
+	c.last = &result
+	return result
+}
@@ -1,18 +1,165 @@
 package main
 
 import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
 	"log"
 	"order_api/app"
+	"order_api/config"
+	"order_api/docs"
+	"order_api/router"
+	"order_api/tlsconfig"
+	"os"
+	"strings"
+
+	"github.com/gin-gonic/gin"
 )
 
+const usage = `usage: order_api [--config FILE] [--KEY=VALUE ...] COMMAND [ARGS]
+
+commands:
+  serve                                    启动HTTP服务（默认）
+  migrate up|down [N]|status|force VERSION 管理数据库迁移
+  user create USERNAME [--password P] [--role admin|user]
+  user disable|enable USERNAME
+  user set-role USERNAME ROLE              管理登录账号
+  apikey create USERNAME [--name N] [--expires 720h]
+                                           创建 API 密钥，明文只输出一次
+  seed --orders N [--users M]              生成测试订单
+  config print [--redacted=false]          输出生效的配置及其来源，默认隐藏密钥
+  config validate                          检查配置和证书文件，供CI和部署前检查使用
+  openapi print|check                      输出 OpenAPI 规范，或检查规范与路由是否一致
+`
+
 func main() {
-	application := app.NewApp()
-	
+	// 配置参数写在子命令之前，如 order_api --config /etc/order_api.json migrate up
+	cfg, args, err := config.Load(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		fmt.Fprint(os.Stderr, usage)
+		return
+	}
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	command := "serve"
+	if len(args) > 0 {
+		command, args = args[0], args[1:]
+	}
+
+	switch command {
+	case "serve":
+		serve(cfg)
+	case "config":
+		if err := configCommand(cfg, args); err != nil {
+			log.Fatalf("Config: %v", err)
+		}
+	case "openapi":
+		if err := openapiCommand(args); err != nil {
+			log.Fatalf("OpenAPI: %v", err)
+		}
+	case "migrate", "user", "apikey", "seed":
+		if err := runCommand(cfg, command, args); err != nil {
+			log.Fatalf("%s failed: %v", command, err)
+		}
+	case "help":
+		fmt.Fprint(os.Stdout, usage)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s", command, usage)
+		os.Exit(2)
+	}
+}
+
+// serve 启动HTTP服务直到收到退出信号
+func serve(cfg *config.Config) {
+	application := app.NewApp(cfg)
 	if err := application.Initialize(); err != nil {
+		application.Shutdown()
 		log.Fatalf("Failed to initialize application: %v", err)
 	}
 
 	if err := application.Run(); err != nil {
 		log.Fatalf("Error running application: %v", err)
 	}
-}
\ No newline at end of file
+}
+
+// runCommand 执行管理命令，命令结束后关闭数据库和缓存连接
+func runCommand(cfg *config.Config, command string, args []string) error {
+	application := app.NewApp(cfg)
+	defer application.Shutdown()
+
+	switch command {
+	case "migrate":
+		return application.Migrate(args)
+	case "user":
+		return application.Users(args)
+	case "apikey":
+		return application.APIKeys(args)
+	default:
+		return application.Seed(args)
+	}
+}
+
+// configCommand 执行 config print|validate，不带参数时等同于 print
+func configCommand(cfg *config.Config, args []string) error {
+	action := "print"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		action, args = args[0], args[1:]
+	}
+
+	switch action {
+	case "print":
+		fs := flag.NewFlagSet("config print", flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		redacted := fs.Bool("redacted", true, "隐藏密码和密钥")
+		if err := fs.Parse(args); err != nil {
+			return err
+		}
+		return cfg.WriteSettings(os.Stdout, *redacted)
+	case "validate":
+		// 配置在加载时已经验证，这里再检查证书文件能否加载
+		if cfg.Server.TLS.Enabled() {
+			if _, err := tlsconfig.New(&cfg.Server.TLS); err != nil {
+				return err
+			}
+		}
+		files := strings.Join(cfg.Files(), ", ")
+		if files == "" {
+			files = "(none)"
+		}
+		fmt.Printf("config is valid, files: %s\n", files)
+		return nil
+	default:
+		return fmt.Errorf("usage: config print [--redacted=false] | validate")
+	}
+}
+
+// openapiCommand 执行 openapi print|check，check 不连接数据库，规范与路由不一致时以非零状态退出
+func openapiCommand(args []string) error {
+	action := "check"
+	if len(args) > 0 {
+		action = args[0]
+	}
+
+	switch action {
+	case "print":
+		_, err := os.Stdout.Write(docs.Spec())
+		return err
+	case "check":
+		// 只需要路由表，处理器不会被调用，因此不需要初始化依赖
+		gin.SetMode(gin.ReleaseMode)
+		if err := docs.CheckRoutes(router.Routes()); err != nil {
+			return err
+		}
+		ops, err := docs.Operations()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("OpenAPI spec matches the router, %d operations\n", len(ops))
+		return nil
+	default:
+		return fmt.Errorf("usage: openapi print | check")
+	}
+}
@@ -0,0 +1,57 @@
+// Package metrics 提供Prometheus指标的注册与暴露
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// queryDuration SQL语句耗时分布
+var queryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+	Name:    "order_api_db_query_duration_seconds",
+	Help:    "SQL statement latency by database, operation and table.",
+	Buckets: []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5},
+}, []string{"db", "operation", "table", "status"})
+
+// healthCheckUp 依赖检查结果，1为正常
+var healthCheckUp = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "order_api_health_check_up",
+	Help: "Whether the last readiness check of a dependency succeeded.",
+}, []string{"check"})
+
+func init() {
+	prometheus.MustRegister(queryDuration, healthCheckUp)
+}
+
+// Handler 返回 /metrics 接口的处理器
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// RegisterDBStats 注册连接池统计指标（go_sql_*），name 用于区分多个数据库
+func RegisterDBStats(db *sql.DB, name string) error {
+	return prometheus.Register(collectors.NewDBStatsCollector(db, name))
+}
+
+// ObserveQuery 记录一条SQL语句的耗时，failed 表示语句执行失败
+func ObserveQuery(db, operation, table string, duration time.Duration, failed bool) {
+	status := "ok"
+	if failed {
+		status = "error"
+	}
+	queryDuration.WithLabelValues(db, operation, table, status).Observe(duration.Seconds())
+}
+
+// SetHealthCheck 记录一次依赖检查的结果
+func SetHealthCheck(check string, ok bool) {
+	value := 0.0
+	if ok {
+		value = 1
+	}
+	healthCheckUp.WithLabelValues(check).Set(value)
+}
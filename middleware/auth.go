@@ -9,9 +9,27 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-// Auth 认证中间件，用于验证请求中的JWT令牌
+// APIKeyHeader 携带 API 密钥的请求头
+const APIKeyHeader = "X-API-Key"
+
+// Auth 认证中间件，用于验证请求中的JWT令牌或 API 密钥
 func Auth(authService *auth.AuthService) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		// 服务间调用使用 API 密钥
+		if key := c.GetHeader(APIKeyHeader); key != "" {
+			claims, err := authService.ValidateAPIKey(c.Request.Context(), key)
+			if err != nil {
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+					"error": "无效或已过期的API密钥",
+				})
+				return
+			}
+			c.Set("user_id", claims.UserID)
+			c.Set("user_role", claims.Role)
+			c.Next()
+			return
+		}
+
 		// 获取Authorization头
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
@@ -46,3 +64,19 @@ func Auth(authService *auth.AuthService) gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// RequireRole 角色校验中间件，需在 Auth 之后使用
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role := c.GetString("user_role")
+		for _, r := range roles {
+			if role == r {
+				c.Next()
+				return
+			}
+		}
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+			"error": "权限不足",
+		})
+	}
+}
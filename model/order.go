@@ -19,10 +19,11 @@ const (
 
 // Order 订单模型
 type Order struct {
-	ID        string         `json:"id" gorm:"primaryKey;type:varchar(36)" label:"订单ID"`
-	UserID    string         `json:"user_id" gorm:"type:varchar(36);index;not null" validate:"required" label:"用户ID"`
-	Status    string         `json:"status" gorm:"type:varchar(20);default:pending" validate:"required,order_status" label:"订单状态"`
-	Amount    float64        `json:"amount" gorm:"type:decimal(10,2)" validate:"gte=0" label:"订单金额"`
+	ID        string         `json:"id" gorm:"primaryKey;size:36" label:"订单ID"`
+	Number    string         `json:"number,omitempty" gorm:"<-:create;size:32;uniqueIndex" label:"订单号"` // 创建后不可修改，早期订单为空
+	UserID    string         `json:"user_id" gorm:"size:36;index;not null" validate:"required" label:"用户ID"`
+	Status    string         `json:"status" gorm:"size:20;default:pending" validate:"required,order_status" label:"订单状态"`
+	Amount    float64        `json:"amount" gorm:"precision:10;scale:2" validate:"gte=0" label:"订单金额"`
 	Items     []OrderItem    `json:"items" gorm:"foreignKey:OrderID" validate:"required,dive" label:"订单项"`
 	CreatedAt time.Time      `json:"created_at" label:"创建时间"`
 	UpdatedAt time.Time      `json:"updated_at" label:"更新时间"`
@@ -31,13 +32,14 @@ type Order struct {
 
 // OrderItem 订单项模型
 type OrderItem struct {
-	ID        string    `json:"id" gorm:"primaryKey;type:varchar(36)" label:"订单项ID"`
-	OrderID   string    `json:"order_id" gorm:"type:varchar(36);index;not null" label:"订单ID"`
-	ProductID string    `json:"product_id" gorm:"type:varchar(36);not null" validate:"required" label:"商品ID"`
-	Quantity  int       `json:"quantity" gorm:"not null" validate:"required,gt=0" label:"商品数量"`
-	Price     float64   `json:"price" gorm:"type:decimal(10,2);not null" validate:"required,gt=0" label:"商品价格"`
-	CreatedAt time.Time `json:"created_at" label:"创建时间"`
-	UpdatedAt time.Time `json:"updated_at" label:"更新时间"`
+	ID        string         `json:"id" gorm:"primaryKey;size:36" label:"订单项ID"`
+	OrderID   string         `json:"order_id" gorm:"size:36;index;not null" label:"订单ID"`
+	ProductID string         `json:"product_id" gorm:"size:36;not null" validate:"required" label:"商品ID"`
+	Quantity  int            `json:"quantity" gorm:"not null" validate:"required,gt=0" label:"商品数量"`
+	Price     float64        `json:"price" gorm:"precision:10;scale:2;not null" validate:"required,gt=0" label:"商品价格"`
+	CreatedAt time.Time      `json:"created_at" label:"创建时间"`
+	UpdatedAt time.Time      `json:"updated_at" label:"更新时间"`
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index" label:"删除时间"` // 随订单一起软删除
 }
 
 // CalculateAmount 计算订单总金额
@@ -0,0 +1,49 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// OrderArchive 归档订单，订单项以 JSON 形式保存
+type OrderArchive struct {
+	ID         string     `json:"id" gorm:"primaryKey;size:36"`
+	Number     string     `json:"number,omitempty" gorm:"size:32;index"`
+	UserID     string     `json:"user_id" gorm:"size:36;index;not null"`
+	Status     string     `json:"status" gorm:"size:20;not null"`
+	Amount     float64    `json:"amount" gorm:"precision:10;scale:2"`
+	Items      string     `json:"items" gorm:"type:text;not null"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+	DeletedAt  *time.Time `json:"deleted_at,omitempty"`
+	ArchivedAt time.Time  `json:"archived_at"`
+}
+
+func (OrderArchive) TableName() string {
+	return "orders_archive"
+}
+
+// NewOrderArchive 将订单及其订单项转换为归档记录
+func NewOrderArchive(order *Order, archivedAt time.Time) (*OrderArchive, error) {
+	items, err := json.Marshal(order.Items)
+	if err != nil {
+		return nil, err
+	}
+
+	archive := &OrderArchive{
+		ID:         order.ID,
+		Number:     order.Number,
+		UserID:     order.UserID,
+		Status:     order.Status,
+		Amount:     order.Amount,
+		Items:      string(items),
+		CreatedAt:  order.CreatedAt,
+		UpdatedAt:  order.UpdatedAt,
+		ArchivedAt: archivedAt,
+	}
+	if order.DeletedAt.Valid {
+		deletedAt := order.DeletedAt.Time
+		archive.DeletedAt = &deletedAt
+	}
+	return archive, nil
+}
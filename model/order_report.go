@@ -0,0 +1,8 @@
+package model
+
+// OrderStatusStat 某一状态的订单数量和金额
+type OrderStatusStat struct {
+	Status string  `json:"status"`
+	Count  int64   `json:"count"`
+	Amount float64 `json:"amount"`
+}
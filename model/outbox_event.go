@@ -0,0 +1,43 @@
+package model
+
+import "time"
+
+// 领域事件类型
+const (
+	EventOrderCreated       = "order.created"        // 订单已创建
+	EventOrderStatusChanged = "order.status_changed" // 订单状态已变更
+)
+
+// 发件箱事件投递状态
+const (
+	OutboxStatusPending   = "pending"   // 等待投递
+	OutboxStatusDelivered = "delivered" // 已投递
+	OutboxStatusDead      = "dead"      // 超过重试次数，进入死信
+)
+
+// OutboxEvent 发件箱事件，与订单变更在同一事务中写入
+type OutboxEvent struct {
+	ID          int64      `json:"id" gorm:"primaryKey"`
+	AggregateID string     `json:"aggregate_id" gorm:"size:36;not null"` // 事件所属订单ID，同一订单的事件按ID顺序投递
+	EventType   string     `json:"event_type" gorm:"size:64;not null"`
+	Payload     string     `json:"payload" gorm:"type:text;not null"` // JSON 格式的事件内容
+	Status      string     `json:"status" gorm:"size:20;not null;default:pending"`
+	Attempts    int        `json:"attempts" gorm:"not null;default:0"`
+	LastError   string     `json:"last_error,omitempty" gorm:"type:text"`
+	AvailableAt time.Time  `json:"available_at" gorm:"not null"` // 最早可投递时间，用于重试退避和投递租约
+	CreatedAt   time.Time  `json:"created_at"`
+	DeliveredAt *time.Time `json:"delivered_at,omitempty"`
+
+	Shard int `json:"shard" gorm:"-"` // 事件所在分片，事件ID只在分片内唯一
+}
+
+// OrderEventPayload 订单事件内容
+type OrderEventPayload struct {
+	OrderID        string    `json:"order_id"`
+	OrderNumber    string    `json:"order_number,omitempty"`
+	UserID         string    `json:"user_id"`
+	Status         string    `json:"status"`
+	PreviousStatus string    `json:"previous_status,omitempty"`
+	Amount         float64   `json:"amount"`
+	OccurredAt     time.Time `json:"occurred_at"`
+}
@@ -0,0 +1,36 @@
+package model
+
+import "time"
+
+// 用户角色
+const (
+	RoleAdmin = "admin" // 管理员，可以访问 /api/v1/admin 下的接口
+	RoleUser  = "user"  // 普通用户，只能访问自己的订单
+)
+
+// ValidRole 判断角色是否有效
+func ValidRole(role string) bool {
+	return role == RoleAdmin || role == RoleUser
+}
+
+// User 登录账号，只保存密码的 bcrypt 摘要
+type User struct {
+	ID           string    `json:"id" gorm:"primaryKey;size:36"`
+	Username     string    `json:"username" gorm:"size:64;not null;uniqueIndex"`
+	PasswordHash string    `json:"-" gorm:"size:255;not null"`
+	Role         string    `json:"role" gorm:"size:20;not null;default:user"`
+	Disabled     bool      `json:"disabled" gorm:"not null;default:false"` // 禁用后不能登录，API 密钥立即失效
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// APIKey 供服务间调用的 API 密钥，只保存密钥的 SHA-256 摘要，权限与所属用户相同
+type APIKey struct {
+	ID        string     `json:"id" gorm:"primaryKey;size:36"`
+	UserID    string     `json:"user_id" gorm:"size:36;not null;index"`
+	Name      string     `json:"name" gorm:"size:64;not null"`
+	Prefix    string     `json:"prefix" gorm:"size:16;not null"` // 密钥开头的几位，用于辨认密钥
+	KeyHash   string     `json:"-" gorm:"size:64;not null;uniqueIndex"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"` // 为空表示不过期
+	CreatedAt time.Time  `json:"created_at"`
+}
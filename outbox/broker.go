@@ -0,0 +1,84 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+)
+
+// Handler 本地订阅者的事件处理函数
+type Handler func(ctx context.Context, msg *Message) error
+
+type subscription struct {
+	id      uint64
+	subject []string
+	handler Handler
+}
+
+// LocalBroker 进程内的主题订阅，主题与事件类型匹配规则同 NATS：
+// 以 . 分隔，* 匹配单个层级，> 匹配其后的所有层级，例如 order.* 或 >
+type LocalBroker struct {
+	mu     sync.RWMutex
+	nextID uint64
+	subs   []subscription
+}
+
+func NewLocalBroker() *LocalBroker {
+	return &LocalBroker{}
+}
+
+// Subscribe 订阅主题，返回取消订阅的函数
+func (b *LocalBroker) Subscribe(subject string, handler Handler) func() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.nextID++
+	id := b.nextID
+	b.subs = append(b.subs, subscription{id: id, subject: strings.Split(subject, "."), handler: handler})
+
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		for i, sub := range b.subs {
+			if sub.id == id {
+				b.subs = append(b.subs[:i:i], b.subs[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// Publish 同步调用所有匹配的订阅者，任一订阅者失败时返回错误，事件将整体重试
+func (b *LocalBroker) Publish(ctx context.Context, msg *Message) error {
+	b.mu.RLock()
+	var handlers []Handler
+	tokens := strings.Split(msg.Type, ".")
+	for _, sub := range b.subs {
+		if matchSubject(sub.subject, tokens) {
+			handlers = append(handlers, sub.handler)
+		}
+	}
+	b.mu.RUnlock()
+
+	var errs []error
+	for _, handler := range handlers {
+		if err := handler(ctx, msg); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// matchSubject 判断事件类型是否匹配订阅主题
+func matchSubject(pattern, tokens []string) bool {
+	for i, p := range pattern {
+		if p == ">" {
+			return i < len(tokens)
+		}
+		if i >= len(tokens) || (p != "*" && p != tokens[i]) {
+			return false
+		}
+	}
+	return len(pattern) == len(tokens)
+}
@@ -0,0 +1,49 @@
+// Package outbox 提供发件箱事件的发布方式
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"order_api/config"
+	"time"
+)
+
+// Message 投递给下游的事件
+type Message struct {
+	ID          int64           `json:"id"`
+	Shard       int             `json:"shard"` // 事件所在分片，ID 只在分片内唯一
+	Type        string          `json:"type"`
+	AggregateID string          `json:"aggregate_id"`
+	Payload     json.RawMessage `json:"payload"`
+	CreatedAt   time.Time       `json:"created_at"`
+}
+
+// Publisher 事件发布接口，返回错误时事件会按退避策略重试
+// 同一事件可能因重试被投递多次，下游应根据 Message.Shard 和 Message.ID 去重
+type Publisher interface {
+	Publish(ctx context.Context, msg *Message) error
+}
+
+// NewPublisher 根据配置创建事件发布器
+func NewPublisher(cfg *config.OutboxConfig) (Publisher, error) {
+	switch cfg.Publisher {
+	case "", config.PublisherLog:
+		return LogPublisher{}, nil
+	case config.PublisherWebhook:
+		return NewWebhookPublisher(&cfg.Webhook), nil
+	case config.PublisherLocal:
+		return NewLocalBroker(), nil
+	default:
+		return nil, fmt.Errorf("unsupported outbox publisher: %s", cfg.Publisher)
+	}
+}
+
+// LogPublisher 将事件写入日志
+type LogPublisher struct{}
+
+func (LogPublisher) Publish(ctx context.Context, msg *Message) error {
+	log.Printf("Outbox event %d/%d %s for %s: %s", msg.Shard, msg.ID, msg.Type, msg.AggregateID, msg.Payload)
+	return nil
+}
@@ -0,0 +1,4 @@
+// Package orderapiv1 gRPC 接口定义生成的代码，修改 .proto 文件后执行 go generate 重新生成
+package orderapiv1
+
+//go:generate protoc -I ../.. --go_out=../../.. --go_opt=module=order_api --go-grpc_out=../../.. --go-grpc_opt=module=order_api orderapi/v1/order.proto orderapi/v1/auth.proto
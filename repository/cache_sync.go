@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"context"
+	"log"
+	"order_api/config"
+	"order_api/model"
+
+	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
+)
+
+// cacheSync 在订单写入事务提交后按策略同步缓存
+type cacheSync struct {
+	db       *gorm.DB
+	cache    Cache
+	strategy string
+}
+
+// registerCacheCallbacks 注册GORM回调，在事务提交或回滚之后执行缓存同步，
+// 保证缓存只反映已提交的数据
+func registerCacheCallbacks(db *gorm.DB, cache Cache, strategy string) error {
+	if strategy == "" {
+		strategy = config.WriteStrategyWriteThrough
+	}
+	s := &cacheSync{db: db, cache: cache, strategy: strategy}
+
+	callbacks := db.Callback()
+	if err := callbacks.Create().After("gorm:commit_or_rollback_transaction").
+		Register("order_api:cache_after_create", s.afterSave); err != nil {
+		return err
+	}
+	if err := callbacks.Update().After("gorm:commit_or_rollback_transaction").
+		Register("order_api:cache_after_update", s.afterSave); err != nil {
+		return err
+	}
+	return callbacks.Delete().After("gorm:commit_or_rollback_transaction").
+		Register("order_api:cache_after_delete", s.afterDelete)
+}
+
+// committedOrder 返回本次语句已成功提交的订单，关联表的语句和失败的语句返回nil
+func committedOrder(db *gorm.DB) *model.Order {
+	if db.Error != nil {
+		return nil
+	}
+	order, ok := db.Statement.Model.(*model.Order)
+	if !ok || order.ID == "" {
+		return nil
+	}
+	return order
+}
+
+// inTransaction 判断语句是否运行在外层事务中，此时数据尚未真正提交
+func inTransaction(db *gorm.DB) bool {
+	_, ok := db.Statement.ConnPool.(gorm.TxCommitter)
+	return ok
+}
+
+func (s *cacheSync) afterSave(db *gorm.DB) {
+	order := committedOrder(db)
+	if order == nil {
+		return
+	}
+
+	ctx := db.Statement.Context
+
+	// 工作单元中的写入推迟到事务提交后同步，回滚时不触碰缓存
+	if uow, ok := unitOfWorkFrom(ctx); ok {
+		id, userID := order.ID, order.UserID
+		uow.AfterCommit(func() { s.syncOrder(ctx, id, userID) })
+		return
+	}
+
+	// 其他外层事务尚未提交时无法读取最终数据，只能先删除缓存
+	if inTransaction(db) {
+		s.invalidate(ctx, order)
+		return
+	}
+	s.syncOrder(ctx, order.ID, order.UserID)
+}
+
+// syncOrder 按写入策略刷新或删除订单缓存
+func (s *cacheSync) syncOrder(ctx context.Context, orderID, userID string) {
+	order := &model.Order{ID: orderID, UserID: userID}
+	if s.strategy == config.WriteStrategyInvalidate {
+		s.invalidate(ctx, order)
+		return
+	}
+
+	// 重新从主库加载，确保缓存与钩子修改后的行一致，且不受副本复制延迟影响
+	var fresh model.Order
+	err := s.db.WithContext(ctx).Clauses(dbresolver.Write).Preload("Items").First(&fresh, "id = ?", orderID).Error
+	if err != nil {
+		log.Printf("Failed to reload order %s for cache: %v", orderID, err)
+		s.invalidate(ctx, order)
+		return
+	}
+	if err := s.cache.SetOrder(ctx, &fresh); err != nil {
+		log.Printf("Failed to write order %s to cache: %v", orderID, err)
+		s.invalidate(ctx, order)
+	}
+}
+
+func (s *cacheSync) afterDelete(db *gorm.DB) {
+	order := committedOrder(db)
+	if order == nil {
+		return
+	}
+
+	ctx := db.Statement.Context
+	if uow, ok := unitOfWorkFrom(ctx); ok {
+		deleted := &model.Order{ID: order.ID, UserID: order.UserID}
+		uow.AfterCommit(func() { s.invalidate(ctx, deleted) })
+		return
+	}
+	s.invalidate(ctx, order)
+}
+
+func (s *cacheSync) invalidate(ctx context.Context, order *model.Order) {
+	if err := s.cache.DeleteOrder(ctx, order.ID, order.UserID); err != nil {
+		log.Printf("Failed to invalidate cached order %s: %v", order.ID, err)
+	}
+}
@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"context"
+	"order_api/errors"
+	"order_api/model"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// orderNumberSequence 订单号的每日计数器
+type orderNumberSequence struct {
+	Scope string `gorm:"primaryKey;size:32"`
+	Value int64  `gorm:"not null"`
+}
+
+func (orderNumberSequence) TableName() string {
+	return "order_number_sequences"
+}
+
+// nextNumber 在当前事务中递增分片的当日计数器并生成订单号
+// 计数器行在事务提交前保持锁定，同一分片同一天的订单号严格递增且不会重复；事务回滚时序号随之回滚
+func (r *OrderRepository) nextNumber(ctx context.Context, shard int, now time.Time) (string, error) {
+	db := conn(ctx, r.cluster, shard).Session(&gorm.Session{})
+	seq := orderNumberSequence{Scope: now.Format("20060102"), Value: 1}
+	if err := incrementSequence(db, &seq).Error; err != nil {
+		return "", err
+	}
+	if err := db.First(&seq, "scope = ?", seq.Scope).Error; err != nil {
+		return "", err
+	}
+	return r.cluster.OrderNumber(r.numberPrefix, shard, now, seq.Value), nil
+}
+
+// incrementSequence 插入计数器行，已存在时加一
+// 递增表达式必须带表名：PostgreSQL 的 ON CONFLICT DO UPDATE 中 EXCLUDED 也有 value 列，不带表名会报 column reference is ambiguous
+func incrementSequence(db *gorm.DB, seq *orderNumberSequence) *gorm.DB {
+	return db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "scope"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{"value": gorm.Expr("order_number_sequences.value + 1")}),
+	}).Create(seq)
+}
+
+// GetByNumber 根据订单号获取订单，按订单号中的分片路由
+func (r *OrderRepository) GetByNumber(ctx context.Context, number string) (*model.Order, error) {
+	shard, ok := r.cluster.ShardForNumber(number)
+	if !ok {
+		return nil, errors.ErrOrderNotFound
+	}
+
+	var order model.Order
+	err := r.reader(ctx, shard).Select("id").Where("number = ?", number).Take(&order).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.ErrOrderNotFound
+		}
+		return nil, errors.Wrap(err, "failed to find order by number")
+	}
+	return r.GetByID(ctx, order.ID)
+}
@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+	"order_api/database/dbtest"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func TestOrderNumbersSameDay(t *testing.T) {
+	repo := newTestOrderRepository(t, dbtest.NewCluster(t, 1))
+
+	first := createTestOrder(t, repo, "alice")
+	second := createTestOrder(t, repo, "bob")
+
+	day := time.Now().Format("20060102")
+	if want := "ORD-" + day + "-000001"; first.Number != want {
+		t.Errorf("first order number = %s, want %s", first.Number, want)
+	}
+	if want := "ORD-" + day + "-000002"; second.Number != want {
+		t.Errorf("second order number = %s, want %s", second.Number, want)
+	}
+}
+
+func TestOrderNumbersPerShard(t *testing.T) {
+	cluster := dbtest.NewCluster(t, 2)
+	repo := newTestOrderRepository(t, cluster)
+	ctx := context.Background()
+
+	now := time.Now()
+	for shard := 0; shard < cluster.Len(); shard++ {
+		for want := int64(1); want <= 2; want++ {
+			var number string
+			err := repo.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+				var err error
+				number, err = repo.nextNumber(ctx, shard, now)
+				return err
+			})
+			if err != nil {
+				t.Fatalf("nextNumber on shard %d: %v", shard, err)
+			}
+			if expected := cluster.OrderNumber("ORD", shard, now, want); number != expected {
+				t.Errorf("shard %d number = %s, want %s", shard, number, expected)
+			}
+		}
+	}
+}
+
+// TestIncrementSequencePostgres 测试环境没有 PostgreSQL，检查生成的 upsert 语句中递增表达式带有表名
+func TestIncrementSequencePostgres(t *testing.T) {
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{
+		DryRun:                 true,
+		DisableAutomaticPing:   true,
+		SkipDefaultTransaction: true,
+	})
+	if err != nil {
+		t.Fatalf("open dry-run postgres: %v", err)
+	}
+
+	result := incrementSequence(db, &orderNumberSequence{Scope: "20261019", Value: 1})
+	if result.Error != nil {
+		t.Fatalf("build upsert: %v", result.Error)
+	}
+	sql := result.Statement.SQL.String()
+	if !strings.Contains(sql, `ON CONFLICT ("scope") DO UPDATE SET "value"=order_number_sequences.value + 1`) {
+		t.Fatalf("upsert does not qualify the sequence column: %s", sql)
+	}
+}
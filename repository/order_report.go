@@ -0,0 +1,26 @@
+package repository
+
+import (
+	"context"
+	"order_api/database"
+	"order_api/errors"
+	"order_api/model"
+	"time"
+)
+
+// StatusStatsByShard 在全部分片上按状态统计 [from, to) 内创建的订单，结果按分片序号排列
+// 报表允许读取只读副本，不包含已删除的订单
+func (r *OrderRepository) StatusStatsByShard(ctx context.Context, from, to time.Time) ([][]model.OrderStatusStat, error) {
+	results := make([][]model.OrderStatusStat, r.cluster.Len())
+	err := r.cluster.FanOut(ctx, func(ctx context.Context, shard int, _ *database.Database) error {
+		return r.reader(ctx, shard).Model(&model.Order{}).
+			Select("status, COUNT(*) AS count, COALESCE(SUM(amount), 0) AS amount").
+			Where("created_at >= ? AND created_at < ?", from, to).
+			Group("status").Order("status").
+			Find(&results[shard]).Error
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to query order stats")
+	}
+	return results, nil
+}
@@ -2,16 +2,23 @@ package repository
 
 import (
 	"context"
+	"order_api/database"
 	"order_api/errors"
 	"order_api/model"
+	"sort"
+	"time"
 
-	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/plugin/dbresolver"
 )
 
+// OrderRepository 订单仓储，订单按 user_id 分布在多个分片上，订单ID中编码了所在分片
 type OrderRepository struct {
-	db    *gorm.DB
-	cache Cache
+	cluster      *database.Cluster
+	tx           *TxManager
+	cache        Cache
+	numberPrefix string
 }
 
 type Cache interface {
@@ -20,35 +27,165 @@ type Cache interface {
 	DeleteOrder(ctx context.Context, orderID string, userID string) error
 }
 
-func NewOrderRepository(db *gorm.DB, cache Cache) *OrderRepository {
+// NewOrderRepository 创建订单仓储，并在每个分片上按写入策略注册事务提交后的缓存同步回调
+func NewOrderRepository(cluster *database.Cluster, cache Cache, writeStrategy string, numberPrefix string) (*OrderRepository, error) {
+	for _, shard := range cluster.Shards() {
+		if err := registerCacheCallbacks(shard.DB, cache, writeStrategy); err != nil {
+			return nil, errors.Wrap(err, "failed to register cache callbacks")
+		}
+	}
 	return &OrderRepository{
-		db:    db,
-		cache: cache,
+		cluster:      cluster,
+		tx:           NewTxManager(cluster),
+		cache:        cache,
+		numberPrefix: numberPrefix,
+	}, nil
+}
+
+// orderShard 返回订单所在的分片，ID 中的分片无效时订单不可能存在
+func (r *OrderRepository) orderShard(orderID string) (int, error) {
+	shard, ok := r.cluster.ShardForOrder(orderID)
+	if !ok {
+		return 0, errors.ErrOrderNotFound
+	}
+	return shard, nil
+}
+
+// reader 返回指定分片上查询使用的会话
+// 事务中使用事务会话；上下文要求或 keys 最近有写入时固定读主库；否则路由到只读副本
+func (r *OrderRepository) reader(ctx context.Context, shard int, keys ...string) *gorm.DB {
+	if _, ok := unitOfWorkFrom(ctx); ok {
+		return conn(ctx, r.cluster, shard)
+	}
+	db := r.cluster.Shard(shard)
+	if database.UsePrimary(ctx) || db.Sticky.Recent(keys...) {
+		return db.WithContext(ctx).Clauses(dbresolver.Write)
 	}
+	return db.WithContext(ctx)
+}
+
+// markWrite 记录订单写入，使该用户和订单的后续查询在复制延迟窗口内读主库
+func (r *OrderRepository) markWrite(order *model.Order) {
+	if shard, ok := r.cluster.ShardForOrder(order.ID); ok {
+		r.cluster.Shard(shard).Sticky.MarkWrite(userKey(order.UserID), orderKey(order.ID))
+	}
+}
+
+func userKey(userID string) string {
+	return "user:" + userID
+}
+
+func orderKey(orderID string) string {
+	return "order:" + orderID
 }
 
 // ListByUserID 获取用户的订单列表
 func (r *OrderRepository) ListByUserID(ctx context.Context, userID string) ([]model.Order, error) {
 	var orders []model.Order
-	if err := r.db.Where("user_id = ?", userID).Find(&orders).Error; err != nil {
+	shard := r.cluster.ShardForUser(userID)
+	if err := r.reader(ctx, shard, userKey(userID)).Where("user_id = ?", userID).Find(&orders).Error; err != nil {
 		return nil, errors.Wrap(err, "failed to list orders")
 	}
 	return orders, nil
 }
 
-// Create 创建订单
+// ListUpdatedSince 获取指定时间之后更新过的订单，按更新时间倒序，跨全部分片查询
+func (r *OrderRepository) ListUpdatedSince(ctx context.Context, since time.Time, limit int) ([]model.Order, error) {
+	results := make([][]model.Order, r.cluster.Len())
+	err := r.cluster.FanOut(ctx, func(ctx context.Context, shard int, _ *database.Database) error {
+		query := r.reader(ctx, shard).Preload("Items").
+			Where("updated_at >= ?", since).
+			Order("updated_at DESC")
+		if limit > 0 {
+			query = query.Limit(limit)
+		}
+		return query.Find(&results[shard]).Error
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list recent orders")
+	}
+
+	orders := mergeOrders(results)
+	sort.SliceStable(orders, func(i, j int) bool {
+		return orders[i].UpdatedAt.After(orders[j].UpdatedAt)
+	})
+	if limit > 0 && len(orders) > limit {
+		orders = orders[:limit]
+	}
+	return orders, nil
+}
+
+// FindByIDs 直接从主库批量获取订单，不经过缓存和只读副本
+func (r *OrderRepository) FindByIDs(ctx context.Context, orderIDs []string) ([]model.Order, error) {
+	byShard := make(map[int][]string)
+	for _, id := range orderIDs {
+		if shard, ok := r.cluster.ShardForOrder(id); ok {
+			byShard[shard] = append(byShard[shard], id)
+		}
+	}
+
+	results := make([][]model.Order, r.cluster.Len())
+	for shard, ids := range byShard {
+		err := conn(ctx, r.cluster, shard).Clauses(dbresolver.Write).Preload("Items").
+			Where("id IN ?", ids).Find(&results[shard]).Error
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to find orders")
+		}
+	}
+	return mergeOrders(results), nil
+}
+
+// mergeOrders 按分片顺序合并各分片的查询结果
+func mergeOrders(results [][]model.Order) []model.Order {
+	var orders []model.Order
+	for _, shardOrders := range results {
+		orders = append(orders, shardOrders...)
+	}
+	return orders
+}
+
+// Create 在用户所在分片的同一事务中写入订单和订单项，上下文中已有事务时加入该事务
+// 订单和订单项使用时间有序的ID，并在同一事务中分配订单号
 func (r *OrderRepository) Create(ctx context.Context, order *model.Order) error {
-	order.ID = uuid.New().String()
-	
-	if err := r.db.Create(order).Error; err != nil {
+	shard := r.cluster.ShardForUser(order.UserID)
+	order.ID = database.NewID(shard)
+	for i := range order.Items {
+		order.Items[i].ID = database.NewID(shard)
+		order.Items[i].OrderID = order.ID
+	}
+
+	// 缓存由提交后回调同步
+	err := r.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		number, err := r.nextNumber(ctx, shard, time.Now())
+		if err != nil {
+			return err
+		}
+		order.Number = number
+
+		db := conn(ctx, r.cluster, shard)
+		if err := db.Omit(clause.Associations).Create(order).Error; err != nil {
+			return err
+		}
+		if len(order.Items) == 0 {
+			return nil
+		}
+		return db.Create(&order.Items).Error
+	})
+	if err != nil {
 		return errors.Wrap(err, "failed to create order")
 	}
+	r.markWrite(order)
 
-	return r.cache.SetOrder(ctx, order)
+	return nil
 }
 
-// GetByID 根据ID获取订单
+// GetByID 根据ID获取订单，按ID中编码的分片路由
 func (r *OrderRepository) GetByID(ctx context.Context, orderID string) (*model.Order, error) {
+	shard, err := r.orderShard(orderID)
+	if err != nil {
+		return nil, err
+	}
+
 	// 先尝试从缓存获取
 	order, err := r.cache.GetOrder(ctx, orderID)
 	if err == nil {
@@ -57,7 +194,7 @@ func (r *OrderRepository) GetByID(ctx context.Context, orderID string) (*model.O
 
 	// 缓存未命中，从数据库获取
 	var dbOrder model.Order
-	if err := r.db.Preload("Items").First(&dbOrder, "id = ?", orderID).Error; err != nil {
+	if err := r.reader(ctx, shard, orderKey(orderID)).Preload("Items").First(&dbOrder, "id = ?", orderID).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, errors.ErrOrderNotFound
 		}
@@ -72,28 +209,72 @@ func (r *OrderRepository) GetByID(ctx context.Context, orderID string) (*model.O
 	return &dbOrder, nil
 }
 
+// GetForUpdate 在事务中读取并锁定订单，不经过缓存，必须在 WithinTransaction 内调用
+func (r *OrderRepository) GetForUpdate(ctx context.Context, orderID string) (*model.Order, error) {
+	if _, ok := unitOfWorkFrom(ctx); !ok {
+		return nil, errors.New("GetForUpdate must be called within a transaction")
+	}
+
+	shard, err := r.orderShard(orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	var order model.Order
+	err = conn(ctx, r.cluster, shard).Clauses(clause.Locking{Strength: "UPDATE"}).
+		Preload("Items").First(&order, "id = ?", orderID).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.ErrOrderNotFound
+		}
+		return nil, errors.Wrap(err, "failed to lock order")
+	}
+	return &order, nil
+}
+
 // Update 更新订单
 func (r *OrderRepository) Update(ctx context.Context, order *model.Order) error {
-	if err := r.db.Save(order).Error; err != nil {
+	shard, err := r.orderShard(order.ID)
+	if err != nil {
+		return err
+	}
+	if err := conn(ctx, r.cluster, shard).Save(order).Error; err != nil {
 		return errors.Wrap(err, "failed to update order")
 	}
+	r.markWrite(order)
 
-	return r.cache.SetOrder(ctx, order)
+	return nil
 }
 
-// Delete 删除订单
+// Delete 在同一事务中锁定并软删除订单及其订单项
 func (r *OrderRepository) Delete(ctx context.Context, orderID string) error {
-	var order model.Order
-	if err := r.db.First(&order, "id = ?", orderID).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return errors.ErrOrderNotFound
-		}
-		return errors.Wrap(err, "failed to find order")
+	shard, err := r.orderShard(orderID)
+	if err != nil {
+		return err
 	}
 
-	if err := r.db.Delete(&order).Error; err != nil {
+	var order model.Order
+	err = r.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		db := conn(ctx, r.cluster, shard)
+		if err := db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&order, "id = ?", orderID).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return errors.ErrOrderNotFound
+			}
+			return errors.Wrap(err, "failed to find order")
+		}
+		// 订单项随订单一起软删除，恢复时一并恢复
+		if err := db.Where("order_id = ?", order.ID).Delete(&model.OrderItem{}).Error; err != nil {
+			return err
+		}
+		return db.Delete(&order).Error
+	})
+	if err != nil {
+		if errors.Is(err, errors.ErrOrderNotFound) {
+			return err
+		}
 		return errors.Wrap(err, "failed to delete order")
 	}
+	r.markWrite(&order)
 
-	return r.cache.DeleteOrder(ctx, orderID, order.UserID)
-}
\ No newline at end of file
+	return nil
+}
@@ -0,0 +1,196 @@
+package repository
+
+import (
+	"context"
+	"order_api/cache"
+	"order_api/config"
+	"order_api/database"
+	"order_api/database/dbtest"
+	"order_api/errors"
+	"order_api/model"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestOrderRepositoryCreateAndGet(t *testing.T) {
+	repo := newTestOrderRepository(t, dbtest.NewCluster(t, 1))
+	ctx := context.Background()
+
+	created := createTestOrder(t, repo, "alice")
+	if created.ID == "" || created.Number == "" {
+		t.Fatalf("created order has no ID or number: %+v", created)
+	}
+	for _, item := range created.Items {
+		if item.ID == "" || item.OrderID != created.ID {
+			t.Fatalf("item not linked to order: %+v", item)
+		}
+	}
+
+	// 绕过缓存直接读库，确认订单和订单项都已提交
+	found, err := repo.FindByIDs(ctx, []string{created.ID})
+	if err != nil {
+		t.Fatalf("FindByIDs: %v", err)
+	}
+	if len(found) != 1 || len(found[0].Items) != 2 || found[0].Amount != 25.5 {
+		t.Fatalf("FindByIDs = %+v, want one order with 2 items and amount 25.5", found)
+	}
+
+	got, err := repo.GetByID(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if got.UserID != "alice" || len(got.Items) != 2 {
+		t.Fatalf("GetByID = %+v", got)
+	}
+
+	byNumber, err := repo.GetByNumber(ctx, created.Number)
+	if err != nil {
+		t.Fatalf("GetByNumber: %v", err)
+	}
+	if byNumber.ID != created.ID {
+		t.Fatalf("GetByNumber returned %s, want %s", byNumber.ID, created.ID)
+	}
+}
+
+func TestOrderRepositoryGetMissing(t *testing.T) {
+	repo := newTestOrderRepository(t, dbtest.NewCluster(t, 1))
+	ctx := context.Background()
+
+	for _, id := range []string{"not-a-uuid", "0192f6a0-0000-7000-8000-000000000000"} {
+		if _, err := repo.GetByID(ctx, id); !errors.Is(err, errors.ErrOrderNotFound) {
+			t.Errorf("GetByID(%q) error = %v, want ErrOrderNotFound", id, err)
+		}
+	}
+	if _, err := repo.GetByNumber(ctx, "ORD-20260101-000001"); !errors.Is(err, errors.ErrOrderNotFound) {
+		t.Errorf("GetByNumber error = %v, want ErrOrderNotFound", err)
+	}
+}
+
+func TestOrderRepositoryListByUserID(t *testing.T) {
+	repo := newTestOrderRepository(t, dbtest.NewCluster(t, 1))
+	ctx := context.Background()
+
+	createTestOrder(t, repo, "alice")
+	createTestOrder(t, repo, "alice")
+	createTestOrder(t, repo, "bob")
+
+	orders, err := repo.ListByUserID(ctx, "alice")
+	if err != nil {
+		t.Fatalf("ListByUserID: %v", err)
+	}
+	if len(orders) != 2 {
+		t.Fatalf("ListByUserID returned %d orders, want 2", len(orders))
+	}
+	for _, order := range orders {
+		if order.UserID != "alice" {
+			t.Errorf("ListByUserID returned order of %s", order.UserID)
+		}
+	}
+}
+
+func TestOrderRepositoryUpdate(t *testing.T) {
+	repo := newTestOrderRepository(t, dbtest.NewCluster(t, 1))
+	ctx := context.Background()
+
+	order := createTestOrder(t, repo, "alice")
+	order.Status = model.StatusPaid
+	if err := repo.Update(ctx, order); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+
+	// 写穿策略在提交后重新加载缓存，读取应看到新状态
+	got, err := repo.GetByID(ctx, order.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if got.Status != model.StatusPaid {
+		t.Fatalf("status = %s, want %s", got.Status, model.StatusPaid)
+	}
+}
+
+func TestOrderRepositoryDeleteAndRestore(t *testing.T) {
+	repo := newTestOrderRepository(t, dbtest.NewCluster(t, 1))
+	ctx := context.Background()
+
+	order := createTestOrder(t, repo, "alice")
+	if err := repo.Delete(ctx, order.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := repo.GetByID(ctx, order.ID); !errors.Is(err, errors.ErrOrderNotFound) {
+		t.Fatalf("GetByID after delete error = %v, want ErrOrderNotFound", err)
+	}
+	if err := repo.Delete(ctx, order.ID); !errors.Is(err, errors.ErrOrderNotFound) {
+		t.Fatalf("second Delete error = %v, want ErrOrderNotFound", err)
+	}
+
+	restored, err := repo.Restore(ctx, order.ID)
+	if err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	if len(restored.Items) != 2 {
+		t.Fatalf("restored order has %d items, want 2", len(restored.Items))
+	}
+	if _, err := repo.GetByID(ctx, order.ID); err != nil {
+		t.Fatalf("GetByID after restore: %v", err)
+	}
+}
+
+// newLaggingReplicaRepository 创建带只读副本的订单仓储，副本是一个从未同步过的空库
+// 写入后删除缓存，读取时缓存未命中，查询按路由规则落到副本
+func newLaggingReplicaRepository(t *testing.T) *OrderRepository {
+	t.Helper()
+	dir := t.TempDir()
+	replica := filepath.Join(dir, "replica.db")
+	dbtest.Open(t, dbtest.Config(replica)).Close()
+
+	cfg := dbtest.Config(filepath.Join(dir, "primary.db"))
+	cfg.Replicas = []config.ReplicaConfig{{DBName: replica}}
+	repo, err := NewOrderRepository(dbtest.Open(t, cfg), cache.NewMemoryCache(&config.RedisConfig{}), config.WriteStrategyInvalidate, "ORD")
+	if err != nil {
+		t.Fatalf("NewOrderRepository: %v", err)
+	}
+	return repo
+}
+
+// TestOrderRepositoryRestoreWithLaggingReplica 副本尚未同步恢复操作时，Restore 仍返回恢复后的订单
+func TestOrderRepositoryRestoreWithLaggingReplica(t *testing.T) {
+	repo := newLaggingReplicaRepository(t)
+	ctx := context.Background()
+
+	order := createTestOrder(t, repo, "alice")
+	if err := repo.Delete(ctx, order.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	restored, err := repo.Restore(ctx, order.ID)
+	if err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	if restored.ID != order.ID || restored.DeletedAt.Valid || len(restored.Items) != 2 {
+		t.Fatalf("restored order = %+v", restored)
+	}
+}
+
+// TestOrderRepositoryListUpdatedSincePrimary 缓存预热通过 WithPrimary 读取主库，不受副本延迟影响
+func TestOrderRepositoryListUpdatedSincePrimary(t *testing.T) {
+	repo := newLaggingReplicaRepository(t)
+	ctx := context.Background()
+	createTestOrder(t, repo, "alice")
+	since := time.Now().Add(-time.Hour)
+
+	recent, err := repo.ListUpdatedSince(database.WithPrimary(ctx), since, 0)
+	if err != nil {
+		t.Fatalf("ListUpdatedSince: %v", err)
+	}
+	if len(recent) != 1 || len(recent[0].Items) != 2 {
+		t.Fatalf("ListUpdatedSince from the primary = %+v, want one order with two items", recent)
+	}
+
+	lagging, err := repo.ListUpdatedSince(ctx, since, 0)
+	if err != nil {
+		t.Fatalf("ListUpdatedSince: %v", err)
+	}
+	if len(lagging) != 0 {
+		t.Fatalf("ListUpdatedSince from the replica returned %d orders, want 0", len(lagging))
+	}
+}
@@ -0,0 +1,170 @@
+package repository
+
+import (
+	"context"
+	"log"
+	"order_api/database"
+	"order_api/errors"
+	"order_api/model"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
+)
+
+// Restore 恢复已软删除的订单及其订单项
+func (r *OrderRepository) Restore(ctx context.Context, orderID string) (*model.Order, error) {
+	shard, err := r.orderShard(orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	var order, restored model.Order
+	err = r.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		// Session 使后续语句各自从干净的条件开始
+		db := conn(ctx, r.cluster, shard).Unscoped().Session(&gorm.Session{})
+		err := db.Where("id = ? AND deleted_at IS NOT NULL", orderID).First(&order).Error
+		if err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return errors.ErrOrderNotFound
+			}
+			return err
+		}
+
+		if err := db.Model(&model.OrderItem{}).Where("order_id = ?", orderID).
+			UpdateColumn("deleted_at", nil).Error; err != nil {
+			return err
+		}
+		// UpdateColumn 跳过 BeforeUpdate 校验，此时订单项尚未加载；缓存由提交后回调同步
+		if err := db.Model(&order).UpdateColumn("deleted_at", nil).Error; err != nil {
+			return err
+		}
+
+		// 在事务内重新加载，提交后不经过缓存和副本读取，避免副本尚未同步时返回订单不存在
+		return conn(ctx, r.cluster, shard).Preload("Items").First(&restored, "id = ?", orderID).Error
+	})
+	if err != nil {
+		if errors.Is(err, errors.ErrOrderNotFound) {
+			return nil, err
+		}
+		return nil, errors.Wrap(err, "failed to restore order")
+	}
+	r.markWrite(&order)
+
+	return &restored, nil
+}
+
+// ListDeletedBefore 获取指定时间之前软删除的订单，包含已软删除的订单项
+// 每个分片最多返回 limit 条，只要有分片还有剩余数据，返回的数量就不少于 limit
+func (r *OrderRepository) ListDeletedBefore(ctx context.Context, before time.Time, limit int) ([]model.Order, error) {
+	orders, err := r.retentionQuery(ctx, func(db *gorm.DB) *gorm.DB {
+		return db.Where("deleted_at IS NOT NULL AND deleted_at < ?", before).
+			Order("deleted_at").Limit(limit)
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list deleted orders")
+	}
+	return orders, nil
+}
+
+// ListCompletedBefore 获取指定时间之前已完成（已送达或已取消）的订单，分批规则同 ListDeletedBefore
+func (r *OrderRepository) ListCompletedBefore(ctx context.Context, before time.Time, limit int) ([]model.Order, error) {
+	orders, err := r.retentionQuery(ctx, func(db *gorm.DB) *gorm.DB {
+		return db.Where("deleted_at IS NULL AND status IN ? AND updated_at < ?",
+			[]string{model.StatusDelivered, model.StatusCancelled}, before).
+			Order("updated_at").Limit(limit)
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list completed orders")
+	}
+	return orders, nil
+}
+
+// retentionQuery 在全部分片上执行保留任务的查询，读取主库且包含软删除的数据
+func (r *OrderRepository) retentionQuery(ctx context.Context, scope func(db *gorm.DB) *gorm.DB) ([]model.Order, error) {
+	results := make([][]model.Order, r.cluster.Len())
+	err := r.cluster.FanOut(ctx, func(ctx context.Context, shard int, db *database.Database) error {
+		query := db.WithContext(ctx).Clauses(dbresolver.Write).Unscoped().
+			Preload("Items", func(db *gorm.DB) *gorm.DB { return db.Unscoped() })
+		return scope(query).Find(&results[shard]).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return mergeOrders(results), nil
+}
+
+// Purge 在同一事务中物理删除订单及其订单项
+func (r *OrderRepository) Purge(ctx context.Context, orders []model.Order) error {
+	return r.removeOrders(ctx, orders, nil)
+}
+
+// ArchiveToTable 在同一事务中将订单写入所在分片的归档表并物理删除原数据
+func (r *OrderRepository) ArchiveToTable(ctx context.Context, orders []model.Order) error {
+	now := time.Now()
+	return r.removeOrders(ctx, orders, func(db *gorm.DB, orders []model.Order) error {
+		archives := make([]*model.OrderArchive, 0, len(orders))
+		for i := range orders {
+			archive, err := model.NewOrderArchive(&orders[i], now)
+			if err != nil {
+				return errors.Wrap(err, "failed to encode order archive")
+			}
+			archives = append(archives, archive)
+		}
+		return db.Create(&archives).Error
+	})
+}
+
+// removeOrders 物理删除订单，before 在删除前于同一事务中执行
+// 每个分片使用独立的事务，前面分片的删除不会因后面分片失败而回滚
+func (r *OrderRepository) removeOrders(ctx context.Context, orders []model.Order, before func(db *gorm.DB, orders []model.Order) error) error {
+	byShard := make([][]model.Order, r.cluster.Len())
+	for _, order := range orders {
+		shard, ok := r.cluster.ShardForOrder(order.ID)
+		if !ok {
+			return errors.Wrap(errors.ErrInvalidOrderID, "failed to remove orders")
+		}
+		byShard[shard] = append(byShard[shard], order)
+	}
+
+	for shard, shardOrders := range byShard {
+		if err := r.removeShardOrders(ctx, shard, shardOrders, before); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *OrderRepository) removeShardOrders(ctx context.Context, shard int, orders []model.Order, before func(db *gorm.DB, orders []model.Order) error) error {
+	if len(orders) == 0 {
+		return nil
+	}
+	ids := make([]string, len(orders))
+	for i, order := range orders {
+		ids[i] = order.ID
+	}
+
+	err := r.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		db := conn(ctx, r.cluster, shard).Unscoped().Session(&gorm.Session{})
+		if before != nil {
+			if err := before(db, orders); err != nil {
+				return err
+			}
+		}
+		if err := db.Where("order_id IN ?", ids).Delete(&model.OrderItem{}).Error; err != nil {
+			return err
+		}
+		return db.Where("id IN ?", ids).Delete(&model.Order{}).Error
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to remove orders")
+	}
+
+	// 批量删除不会触发单条订单的缓存回调，逐条清理缓存
+	for _, order := range orders {
+		if err := r.cache.DeleteOrder(ctx, order.ID, order.UserID); err != nil {
+			log.Printf("Failed to invalidate cached order %s: %v", order.ID, err)
+		}
+	}
+	return nil
+}
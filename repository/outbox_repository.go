@@ -0,0 +1,212 @@
+package repository
+
+import (
+	"context"
+	"order_api/database"
+	"order_api/errors"
+	"order_api/model"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
+)
+
+// OutboxRepository 发件箱事件仓储，事件与所属订单存放在同一分片
+type OutboxRepository struct {
+	cluster *database.Cluster
+}
+
+func NewOutboxRepository(cluster *database.Cluster) *OutboxRepository {
+	return &OutboxRepository{cluster: cluster}
+}
+
+// Shards 返回分片数量，投递方按分片逐个领取事件
+func (r *OutboxRepository) Shards() int {
+	return r.cluster.Len()
+}
+
+// writer 返回指定分片读写主库的会话，投递状态不能读取存在复制延迟的副本
+func (r *OutboxRepository) writer(ctx context.Context, shard int) *gorm.DB {
+	return conn(ctx, r.cluster, shard).Clauses(dbresolver.Write)
+}
+
+// Append 写入待投递事件，应与业务数据在同一工作单元中调用
+// 事件写入所属订单的分片，保证与订单变更在同一事务中提交
+func (r *OutboxRepository) Append(ctx context.Context, events ...*model.OutboxEvent) error {
+	now := time.Now()
+	byShard := make([][]*model.OutboxEvent, r.cluster.Len())
+	for _, event := range events {
+		shard, ok := r.cluster.ShardForOrder(event.AggregateID)
+		if !ok {
+			return errors.Wrap(errors.ErrInvalidOrderID, "failed to append outbox events")
+		}
+		event.Shard = shard
+		event.Status = model.OutboxStatusPending
+		if event.AvailableAt.IsZero() {
+			event.AvailableAt = now
+		}
+		byShard[shard] = append(byShard[shard], event)
+	}
+
+	for shard, shardEvents := range byShard {
+		if len(shardEvents) == 0 {
+			continue
+		}
+		if err := conn(ctx, r.cluster, shard).Create(&shardEvents).Error; err != nil {
+			return errors.Wrap(err, "failed to append outbox events")
+		}
+	}
+	return nil
+}
+
+// Claim 领取一批可投递的事件，并在 lease 时间内阻止其他实例重复领取
+// 每个订单只领取最早的一条待投递事件，前一条投递成功或进入死信后才会领取下一条，保证同一订单的事件按序投递
+func (r *OutboxRepository) Claim(ctx context.Context, shard int, limit int, lease time.Duration) ([]model.OutboxEvent, error) {
+	now := time.Now()
+
+	var heads []model.OutboxEvent
+	err := r.writer(ctx, shard).
+		Where("status = ? AND available_at <= ?", model.OutboxStatusPending, now).
+		Where("NOT EXISTS (SELECT 1 FROM outbox_events earlier WHERE earlier.aggregate_id = outbox_events.aggregate_id AND earlier.status = ? AND earlier.id < outbox_events.id)", model.OutboxStatusPending).
+		Order("id").
+		Limit(limit).
+		Find(&heads).Error
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to query outbox events")
+	}
+
+	claimed := make([]model.OutboxEvent, 0, len(heads))
+	for _, event := range heads {
+		// 条件更新保证同一事件只会被一个实例领取
+		result := r.writer(ctx, shard).Model(&model.OutboxEvent{}).
+			Where("id = ? AND status = ? AND available_at <= ?", event.ID, model.OutboxStatusPending, now).
+			Update("available_at", now.Add(lease))
+		if result.Error != nil {
+			return claimed, errors.Wrap(result.Error, "failed to claim outbox event")
+		}
+		if result.RowsAffected == 1 {
+			event.Shard = shard
+			claimed = append(claimed, event)
+		}
+	}
+	return claimed, nil
+}
+
+// MarkDelivered 标记事件投递成功
+func (r *OutboxRepository) MarkDelivered(ctx context.Context, shard int, id int64) error {
+	err := r.writer(ctx, shard).Model(&model.OutboxEvent{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status":       model.OutboxStatusDelivered,
+		"delivered_at": time.Now(),
+		"last_error":   "",
+	}).Error
+	if err != nil {
+		return errors.Wrap(err, "failed to mark outbox event delivered")
+	}
+	return nil
+}
+
+// MarkFailed 记录投递失败，dead 为 true 时事件进入死信，否则在 retryAt 之后重试
+func (r *OutboxRepository) MarkFailed(ctx context.Context, shard int, id int64, cause string, retryAt time.Time, dead bool) error {
+	updates := map[string]interface{}{
+		"attempts":     gorm.Expr("attempts + 1"),
+		"last_error":   cause,
+		"available_at": retryAt,
+	}
+	if dead {
+		updates["status"] = model.OutboxStatusDead
+	}
+	if err := r.writer(ctx, shard).Model(&model.OutboxEvent{}).Where("id = ?", id).Updates(updates).Error; err != nil {
+		return errors.Wrap(err, "failed to mark outbox event failed")
+	}
+	return nil
+}
+
+// ListDead 获取全部分片的死信事件，按创建时间倒序
+func (r *OutboxRepository) ListDead(ctx context.Context, limit int) ([]model.OutboxEvent, error) {
+	results := make([][]model.OutboxEvent, r.cluster.Len())
+	err := r.cluster.FanOut(ctx, func(ctx context.Context, shard int, _ *database.Database) error {
+		return r.writer(ctx, shard).Where("status = ?", model.OutboxStatusDead).
+			Order("id DESC").Limit(limit).Find(&results[shard]).Error
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list dead outbox events")
+	}
+
+	events := []model.OutboxEvent{}
+	for shard := range results {
+		for _, event := range results[shard] {
+			event.Shard = shard
+			events = append(events, event)
+		}
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].CreatedAt.After(events[j].CreatedAt)
+	})
+	if len(events) > limit {
+		events = events[:limit]
+	}
+	return events, nil
+}
+
+// OldestReadyAt 返回全部分片中已到投递时间但尚未投递的最早事件的可投递时间，没有积压时返回 false
+// 投递中的事件持有租约，可投递时间在未来，不计入积压
+func (r *OutboxRepository) OldestReadyAt(ctx context.Context) (time.Time, bool, error) {
+	now := time.Now()
+	results := make([][]model.OutboxEvent, r.cluster.Len())
+	err := r.cluster.FanOut(ctx, func(ctx context.Context, shard int, _ *database.Database) error {
+		return r.writer(ctx, shard).Select("available_at").
+			Where("status = ? AND available_at <= ?", model.OutboxStatusPending, now).
+			Order("available_at").Limit(1).Find(&results[shard]).Error
+	})
+	if err != nil {
+		return time.Time{}, false, errors.Wrap(err, "failed to query outbox backlog")
+	}
+
+	var oldest time.Time
+	found := false
+	for _, events := range results {
+		for _, event := range events {
+			if !found || event.AvailableAt.Before(oldest) {
+				oldest, found = event.AvailableAt, true
+			}
+		}
+	}
+	return oldest, found, nil
+}
+
+// Requeue 将死信事件重新放回投递队列
+func (r *OutboxRepository) Requeue(ctx context.Context, shard int, id int64) error {
+	if shard < 0 || shard >= r.cluster.Len() {
+		return errors.ErrEventNotFound
+	}
+	result := r.writer(ctx, shard).Model(&model.OutboxEvent{}).
+		Where("id = ? AND status = ?", id, model.OutboxStatusDead).
+		Updates(map[string]interface{}{
+			"status":       model.OutboxStatusPending,
+			"attempts":     0,
+			"available_at": time.Now(),
+		})
+	if result.Error != nil {
+		return errors.Wrap(result.Error, "failed to requeue outbox event")
+	}
+	if result.RowsAffected == 0 {
+		return errors.ErrEventNotFound
+	}
+	return nil
+}
+
+// PurgeDelivered 删除全部分片中指定时间之前投递成功的事件，返回删除数量
+func (r *OutboxRepository) PurgeDelivered(ctx context.Context, before time.Time) (int64, error) {
+	var total int64
+	for shard := 0; shard < r.cluster.Len(); shard++ {
+		result := r.writer(ctx, shard).
+			Where("status = ? AND delivered_at < ?", model.OutboxStatusDelivered, before).
+			Delete(&model.OutboxEvent{})
+		if result.Error != nil {
+			return total, errors.Wrap(result.Error, "failed to purge outbox events")
+		}
+		total += result.RowsAffected
+	}
+	return total, nil
+}
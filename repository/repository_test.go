@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"context"
+	"order_api/cache"
+	"order_api/config"
+	"order_api/database"
+	"order_api/model"
+	"testing"
+)
+
+// newTestOrderRepository 创建使用进程内缓存的订单仓储
+func newTestOrderRepository(t *testing.T, cluster *database.Cluster) *OrderRepository {
+	t.Helper()
+	repo, err := NewOrderRepository(cluster, cache.NewMemoryCache(&config.RedisConfig{}), config.WriteStrategyWriteThrough, "ORD")
+	if err != nil {
+		t.Fatalf("NewOrderRepository: %v", err)
+	}
+	return repo
+}
+
+// createTestOrder 为用户创建包含两个订单项的待支付订单
+func createTestOrder(t *testing.T, repo *OrderRepository, userID string) *model.Order {
+	t.Helper()
+	order := &model.Order{
+		UserID: userID,
+		Status: model.StatusPending,
+		Items: []model.OrderItem{
+			{ProductID: "p1", Quantity: 2, Price: 10},
+			{ProductID: "p2", Quantity: 1, Price: 5.5},
+		},
+	}
+	order.CalculateAmount()
+	if err := repo.Create(context.Background(), order); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	return order
+}
@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"order_api/database/dbtest"
+	"testing"
+	"time"
+)
+
+// TestOrderRepositoryAcrossShards 两个 SQLite 文件模拟分片，订单按 user_id 写入对应分片，按ID、订单号和用户都能查到
+func TestOrderRepositoryAcrossShards(t *testing.T) {
+	cluster := dbtest.NewFileCluster(t, 2)
+	repo := newTestOrderRepository(t, cluster)
+	ctx := context.Background()
+
+	perShard := make([]int64, cluster.Len())
+	byUser := make(map[string][]string)
+	for i := 0; i < 8; i++ {
+		userID := fmt.Sprintf("user-%d", i)
+		for j := 0; j < 2; j++ {
+			order := createTestOrder(t, repo, userID)
+			byUser[userID] = append(byUser[userID], order.ID)
+
+			shard, ok := cluster.ShardForOrder(order.ID)
+			if !ok || shard != cluster.ShardForUser(userID) {
+				t.Fatalf("order %s of %s encodes shard %d, want %d", order.ID, userID, shard, cluster.ShardForUser(userID))
+			}
+			if numberShard, ok := cluster.ShardForNumber(order.Number); !ok || numberShard != shard {
+				t.Fatalf("order number %s encodes shard %d, want %d", order.Number, numberShard, shard)
+			}
+			perShard[shard]++
+		}
+	}
+	for shard, want := range perShard {
+		if want == 0 {
+			t.Fatalf("no orders were placed on shard %d, pick other user IDs", shard)
+		}
+		var count int64
+		if err := cluster.Shard(shard).Table("orders").Count(&count).Error; err != nil {
+			t.Fatalf("count orders on shard %d: %v", shard, err)
+		}
+		if count != want {
+			t.Errorf("shard %d holds %d orders, want %d", shard, count, want)
+		}
+	}
+
+	for userID, ids := range byUser {
+		orders, err := repo.ListByUserID(ctx, userID)
+		if err != nil {
+			t.Fatalf("ListByUserID(%s): %v", userID, err)
+		}
+		if len(orders) != len(ids) {
+			t.Errorf("ListByUserID(%s) returned %d orders, want %d", userID, len(orders), len(ids))
+		}
+		for _, id := range ids {
+			order, err := repo.GetByID(ctx, id)
+			if err != nil {
+				t.Fatalf("GetByID(%s): %v", id, err)
+			}
+			if order.UserID != userID {
+				t.Errorf("GetByID(%s) returned order of %s, want %s", id, order.UserID, userID)
+			}
+			byNumber, err := repo.GetByNumber(ctx, order.Number)
+			if err != nil || byNumber.ID != id {
+				t.Errorf("GetByNumber(%s) = %v, %v, want order %s", order.Number, byNumber, err, id)
+			}
+		}
+	}
+
+	// 跨分片查询合并全部分片的结果
+	recent, err := repo.ListUpdatedSince(ctx, time.Now().Add(-time.Hour), 0)
+	if err != nil {
+		t.Fatalf("ListUpdatedSince: %v", err)
+	}
+	if len(recent) != 16 {
+		t.Errorf("ListUpdatedSince returned %d orders, want 16", len(recent))
+	}
+}
@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"order_api/database"
+	"order_api/errors"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+type unitOfWorkKey struct{}
+
+// unitOfWork 一次事务的上下文，记录事务提交后需要执行的操作
+// 事务在第一次访问数据库时于对应分片上开启，之后只能访问同一分片，不支持跨分片事务
+type unitOfWork struct {
+	cluster     *database.Cluster
+	mu          sync.Mutex
+	shard       int
+	tx          *gorm.DB
+	afterCommit []func()
+}
+
+// session 返回指定分片上的事务会话，首次调用时开启事务
+func (u *unitOfWork) session(ctx context.Context, shard int) *gorm.DB {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if u.tx == nil {
+		u.shard = shard
+		u.tx = u.cluster.Shard(shard).WithContext(ctx).Begin()
+	} else if u.shard != shard {
+		db := u.cluster.Shard(shard).WithContext(ctx)
+		db.AddError(fmt.Errorf("%w: shard %d and shard %d", errors.ErrCrossShard, u.shard, shard))
+		return db
+	}
+	return u.tx.WithContext(ctx)
+}
+
+// AfterCommit 注册事务提交后执行的操作，事务回滚时丢弃
+func (u *unitOfWork) AfterCommit(fn func()) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.afterCommit = append(u.afterCommit, fn)
+}
+
+// commit 提交事务并执行提交后的操作，未访问过数据库时只执行提交后的操作
+func (u *unitOfWork) commit() error {
+	u.mu.Lock()
+	tx := u.tx
+	hooks := u.afterCommit
+	u.afterCommit = nil
+	u.mu.Unlock()
+
+	if tx != nil {
+		if err := tx.Commit().Error; err != nil {
+			return err
+		}
+	}
+	for _, fn := range hooks {
+		fn()
+	}
+	return nil
+}
+
+func (u *unitOfWork) rollback() {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.tx != nil && u.tx.Error == nil {
+		u.tx.Rollback()
+	}
+	u.afterCommit = nil
+}
+
+// unitOfWorkFrom 返回上下文中正在进行的事务
+func unitOfWorkFrom(ctx context.Context) (*unitOfWork, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+	uow, ok := ctx.Value(unitOfWorkKey{}).(*unitOfWork)
+	return uow, ok
+}
+
+// TxManager 在同一数据库事务中执行多个仓储操作
+// 事务通过 context 传递，仓储方法从上下文中取出事务会话，因此服务层无需感知 gorm 和分片
+type TxManager struct {
+	cluster *database.Cluster
+}
+
+func NewTxManager(cluster *database.Cluster) *TxManager {
+	return &TxManager{cluster: cluster}
+}
+
+// WithinTransaction 在事务中执行 fn，fn 返回错误或 panic 时回滚
+// 上下文中已有事务时直接加入外层事务，由外层统一提交
+func (m *TxManager) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := unitOfWorkFrom(ctx); ok {
+		return fn(ctx)
+	}
+
+	uow := &unitOfWork{cluster: m.cluster}
+	panicked := true
+	defer func() {
+		if panicked {
+			uow.rollback()
+		}
+	}()
+
+	err := fn(context.WithValue(ctx, unitOfWorkKey{}, uow))
+	panicked = false
+	if err != nil {
+		uow.rollback()
+		return err
+	}
+	return uow.commit()
+}
+
+// conn 返回当前上下文在指定分片上使用的数据库会话，处于事务中时使用事务会话
+func conn(ctx context.Context, cluster *database.Cluster, shard int) *gorm.DB {
+	if uow, ok := unitOfWorkFrom(ctx); ok {
+		return uow.session(ctx, shard)
+	}
+	return cluster.Shard(shard).WithContext(ctx)
+}
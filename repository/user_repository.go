@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+	"order_api/database"
+	"order_api/errors"
+	"order_api/model"
+
+	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
+)
+
+// accountShard 账号和 API 密钥所在的分片，这两张表数据量小，不参与分片
+const accountShard = 0
+
+// UserRepository 账号和 API 密钥仓储
+type UserRepository struct {
+	cluster *database.Cluster
+}
+
+func NewUserRepository(cluster *database.Cluster) *UserRepository {
+	return &UserRepository{cluster: cluster}
+}
+
+// db 返回账号分片主库的会话，禁用账号和修改角色需要立即生效，不读取存在复制延迟的副本
+func (r *UserRepository) db(ctx context.Context) *gorm.DB {
+	return r.cluster.Shard(accountShard).WithContext(ctx).Clauses(dbresolver.Write)
+}
+
+// Create 创建账号，用户名已存在时返回 ErrUserExists
+func (r *UserRepository) Create(ctx context.Context, user *model.User) error {
+	var count int64
+	if err := r.db(ctx).Model(&model.User{}).Where("username = ?", user.Username).Count(&count).Error; err != nil {
+		return errors.Wrap(err, "failed to check username")
+	}
+	if count > 0 {
+		return errors.ErrUserExists
+	}
+	if err := r.db(ctx).Create(user).Error; err != nil {
+		return errors.Wrap(err, "failed to create user")
+	}
+	return nil
+}
+
+// GetByUsername 按用户名查询账号
+func (r *UserRepository) GetByUsername(ctx context.Context, username string) (*model.User, error) {
+	var user model.User
+	if err := r.db(ctx).First(&user, "username = ?", username).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.ErrUserNotFound
+		}
+		return nil, errors.Wrap(err, "failed to get user")
+	}
+	return &user, nil
+}
+
+// GetByID 按ID查询账号
+func (r *UserRepository) GetByID(ctx context.Context, id string) (*model.User, error) {
+	var user model.User
+	if err := r.db(ctx).First(&user, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.ErrUserNotFound
+		}
+		return nil, errors.Wrap(err, "failed to get user")
+	}
+	return &user, nil
+}
+
+// Update 按用户名更新账号的指定字段
+func (r *UserRepository) Update(ctx context.Context, username string, updates map[string]interface{}) error {
+	result := r.db(ctx).Model(&model.User{}).Where("username = ?", username).Updates(updates)
+	if result.Error != nil {
+		return errors.Wrap(result.Error, "failed to update user")
+	}
+	if result.RowsAffected == 0 {
+		return errors.ErrUserNotFound
+	}
+	return nil
+}
+
+// CreateAPIKey 保存 API 密钥
+func (r *UserRepository) CreateAPIKey(ctx context.Context, key *model.APIKey) error {
+	if err := r.db(ctx).Create(key).Error; err != nil {
+		return errors.Wrap(err, "failed to create API key")
+	}
+	return nil
+}
+
+// GetAPIKeyByHash 按密钥摘要查询 API 密钥，不存在时返回 ErrUnauthorized
+func (r *UserRepository) GetAPIKeyByHash(ctx context.Context, hash string) (*model.APIKey, error) {
+	var key model.APIKey
+	if err := r.db(ctx).First(&key, "key_hash = ?", hash).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.ErrUnauthorized
+		}
+		return nil, errors.Wrap(err, "failed to get API key")
+	}
+	return &key, nil
+}
@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"context"
+	"order_api/database/dbtest"
+	"order_api/errors"
+	"order_api/model"
+	"testing"
+)
+
+func TestUserRepository(t *testing.T) {
+	repo := NewUserRepository(dbtest.NewCluster(t, 1))
+	ctx := context.Background()
+
+	user := &model.User{ID: "u1", Username: "alice", PasswordHash: "hash", Role: model.RoleUser}
+	if err := repo.Create(ctx, user); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if err := repo.Create(ctx, &model.User{ID: "u2", Username: "alice", PasswordHash: "hash"}); !errors.Is(err, errors.ErrUserExists) {
+		t.Fatalf("duplicate Create error = %v, want ErrUserExists", err)
+	}
+
+	if err := repo.Update(ctx, "alice", map[string]interface{}{"role": model.RoleAdmin, "disabled": true}); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	got, err := repo.GetByUsername(ctx, "alice")
+	if err != nil {
+		t.Fatalf("GetByUsername: %v", err)
+	}
+	if got.Role != model.RoleAdmin || !got.Disabled {
+		t.Fatalf("GetByUsername = %+v, want disabled admin", got)
+	}
+
+	if _, err := repo.GetByUsername(ctx, "bob"); !errors.Is(err, errors.ErrUserNotFound) {
+		t.Fatalf("GetByUsername(bob) error = %v, want ErrUserNotFound", err)
+	}
+	if err := repo.Update(ctx, "bob", map[string]interface{}{"role": model.RoleAdmin}); !errors.Is(err, errors.ErrUserNotFound) {
+		t.Fatalf("Update(bob) error = %v, want ErrUserNotFound", err)
+	}
+}
+
+func TestUserRepositoryAPIKey(t *testing.T) {
+	repo := NewUserRepository(dbtest.NewCluster(t, 1))
+	ctx := context.Background()
+
+	key := &model.APIKey{ID: "k1", UserID: "u1", Name: "ci", Prefix: "oak_abcd", KeyHash: "digest"}
+	if err := repo.CreateAPIKey(ctx, key); err != nil {
+		t.Fatalf("CreateAPIKey: %v", err)
+	}
+	got, err := repo.GetAPIKeyByHash(ctx, "digest")
+	if err != nil {
+		t.Fatalf("GetAPIKeyByHash: %v", err)
+	}
+	if got.ID != "k1" || got.UserID != "u1" {
+		t.Fatalf("GetAPIKeyByHash = %+v", got)
+	}
+	if _, err := repo.GetAPIKeyByHash(ctx, "unknown"); !errors.Is(err, errors.ErrUnauthorized) {
+		t.Fatalf("GetAPIKeyByHash(unknown) error = %v, want ErrUnauthorized", err)
+	}
+}
@@ -3,12 +3,44 @@ package router
 import (
 	"order_api/app/auth"
 	"order_api/handler"
+	"order_api/metrics"
 	"order_api/middleware"
+	"order_api/model"
 
 	"github.com/gin-gonic/gin"
 )
 
-func SetupRouter(orderHandler *handler.OrderHandler, authHandler *handler.AuthHandler, authService *auth.AuthService) *gin.Engine {
+// Deps 路由依赖的处理器和认证服务
+type Deps struct {
+	OrderHandler       *handler.OrderHandler
+	AuthHandler        *handler.AuthHandler
+	CacheHandler       *handler.CacheHandler
+	DiagnosticsHandler *handler.DiagnosticsHandler
+	OutboxHandler      *handler.OutboxHandler
+	RetentionHandler   *handler.RetentionHandler
+	ReportHandler      *handler.ReportHandler
+	HealthHandler      *handler.HealthHandler
+	DocsHandler        *handler.DocsHandler
+	AuthService        *auth.AuthService
+}
+
+// Routes 返回服务注册的路由表，不需要初始化依赖，供 openapi check 和测试比对 OpenAPI 规范
+// 与 SetupRouter 使用同一份路由注册代码，因此不会与实际服务的路由不一致
+func Routes() gin.RoutesInfo {
+	return SetupRouter(Deps{}).Routes()
+}
+
+func SetupRouter(deps Deps) *gin.Engine {
+	orderHandler := deps.OrderHandler
+	authHandler := deps.AuthHandler
+	cacheHandler := deps.CacheHandler
+	diagnosticsHandler := deps.DiagnosticsHandler
+	outboxHandler := deps.OutboxHandler
+	retentionHandler := deps.RetentionHandler
+	reportHandler := deps.ReportHandler
+	healthHandler := deps.HealthHandler
+	docsHandler := deps.DocsHandler
+
 	router := gin.New()
 
 	// 添加中间件
@@ -20,12 +52,17 @@ func SetupRouter(orderHandler *handler.OrderHandler, authHandler *handler.AuthHa
 	// 注册验证器
 	handler.RegisterValidators()
 
-	// 健康检查
-	router.GET("/health", func(c *gin.Context) {
-		c.JSON(200, gin.H{
-			"status": "ok",
-		})
-	})
+	// 存活和就绪检查，/health 与 /readyz 相同，保留给旧的探针配置
+	router.GET("/livez", healthHandler.Livez)
+	router.GET("/readyz", healthHandler.Readyz)
+	router.GET("/health", healthHandler.Readyz)
+
+	// Prometheus 指标
+	router.GET("/metrics", gin.WrapH(metrics.Handler()))
+
+	// OpenAPI 规范和交互式文档，修改路由时需要同步更新 docs/openapi.json
+	router.GET("/openapi.json", docsHandler.OpenAPI)
+	router.GET("/docs/*filepath", docsHandler.UI)
 
 	// 认证路由
 	auth := router.Group("/api/v1/auth")
@@ -37,7 +74,7 @@ func SetupRouter(orderHandler *handler.OrderHandler, authHandler *handler.AuthHa
 	v1 := router.Group("/api/v1")
 	{
 		// 添加认证中间件
-		v1.Use(middleware.Auth(authService))
+		v1.Use(middleware.Auth(deps.AuthService))
 
 		orders := v1.Group("/orders")
 		{
@@ -46,6 +83,25 @@ func SetupRouter(orderHandler *handler.OrderHandler, authHandler *handler.AuthHa
 			orders.PUT("/:id", orderHandler.UpdateOrder)
 			orders.DELETE("/:id", orderHandler.DeleteOrder)
 		}
+
+		// 管理员路由
+		admin := v1.Group("/admin")
+		admin.Use(middleware.RequireRole(model.RoleAdmin))
+		{
+			admin.GET("/cache/orders/:id", cacheHandler.InspectOrder)
+			admin.DELETE("/cache/orders/:id", cacheHandler.EvictOrder)
+			admin.DELETE("/cache/users/:user_id", cacheHandler.EvictUser)
+			admin.POST("/cache/local/flush", cacheHandler.FlushLocal)
+			admin.POST("/cache/warmup", cacheHandler.Warmup)
+			admin.GET("/cache/consistency", cacheHandler.ConsistencyReport)
+			admin.POST("/cache/consistency", cacheHandler.CheckConsistency)
+			admin.GET("/diagnostics/db", diagnosticsHandler.DBStats)
+			admin.GET("/outbox/dead", outboxHandler.DeadLetters)
+			admin.POST("/outbox/events/:id/retry", outboxHandler.Retry)
+			admin.POST("/orders/:id/restore", orderHandler.RestoreOrder)
+			admin.POST("/retention/run", retentionHandler.Run)
+			admin.GET("/reports/orders", reportHandler.Orders)
+		}
 	}
 
 	return router
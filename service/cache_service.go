@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"log"
+	"order_api/cache"
+	"order_api/config"
+	"order_api/database"
+	"order_api/errors"
+	"order_api/repository"
+	"sync"
+	"time"
+)
+
+// WarmupResult 缓存预热结果
+type WarmupResult struct {
+	Loaded   int    `json:"loaded"`
+	Failed   int    `json:"failed"`
+	Duration string `json:"duration"`
+}
+
+type CacheService struct {
+	repo   *repository.OrderRepository
+	store  cache.Store
+	warmup config.CacheWarmupConfig
+}
+
+func NewCacheService(repo *repository.OrderRepository, store cache.Store, cfg *config.RedisConfig) *CacheService {
+	return &CacheService{
+		repo:   repo,
+		store:  store,
+		warmup: cfg.Warmup,
+	}
+}
+
+// Warmup 预热最近活跃的订单，使用有限的并发写入缓存
+func (s *CacheService) Warmup(ctx context.Context) (*WarmupResult, error) {
+	start := time.Now()
+	hours := s.warmup.Hours
+	if hours <= 0 {
+		hours = 24
+	}
+	concurrency := s.warmup.Concurrency
+	if concurrency <= 0 {
+		concurrency = 4
+	}
+
+	// 从主库加载，避免把副本上尚未同步的旧数据写入缓存并保留整个 TTL
+	orders, err := s.repo.ListUpdatedSince(database.WithPrimary(ctx), start.Add(-time.Duration(hours)*time.Hour), s.warmup.Limit)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load orders for warmup")
+	}
+
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		result WarmupResult
+		sem    = make(chan struct{}, concurrency)
+	)
+	for i := range orders {
+		if ctx.Err() != nil {
+			break
+		}
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(i int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			err := s.store.SetOrder(ctx, &orders[i])
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				result.Failed++
+				return
+			}
+			result.Loaded++
+		}(i)
+	}
+	wg.Wait()
+
+	result.Duration = time.Since(start).String()
+	return &result, ctx.Err()
+}
+
+// WarmupOnStartup 启动时按配置执行预热，由调用方放在后台运行，ctx 取消时提前结束
+func (s *CacheService) WarmupOnStartup(ctx context.Context) {
+	if !s.warmup.Enabled {
+		return
+	}
+	result, err := s.Warmup(ctx)
+	if err != nil {
+		log.Printf("Cache warmup failed: %v", err)
+		return
+	}
+	log.Printf("Cache warmup finished: loaded=%d failed=%d duration=%s", result.Loaded, result.Failed, result.Duration)
+}
+
+// InspectOrder 查看订单缓存状态
+func (s *CacheService) InspectOrder(ctx context.Context, orderID string) (*cache.EntryInfo, error) {
+	return s.store.InspectOrder(ctx, orderID)
+}
+
+// EvictOrder 删除单个订单缓存
+func (s *CacheService) EvictOrder(ctx context.Context, orderID string) error {
+	return s.store.EvictOrder(ctx, orderID)
+}
+
+// EvictUser 删除用户的全部订单缓存
+func (s *CacheService) EvictUser(ctx context.Context, userID string) (int, error) {
+	return s.store.EvictUser(ctx, userID)
+}
+
+// FlushLocal 清空所有节点的本地缓存
+func (s *CacheService) FlushLocal(ctx context.Context) error {
+	return s.store.FlushLocal(ctx)
+}
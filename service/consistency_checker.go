@@ -0,0 +1,160 @@
+package service
+
+import (
+	"context"
+	"log"
+	"order_api/cache"
+	"order_api/config"
+	"order_api/errors"
+	"order_api/model"
+	"order_api/repository"
+	"sync"
+	"time"
+)
+
+// Drift 缓存与数据库不一致的订单
+type Drift struct {
+	OrderID  string `json:"order_id"`
+	Reason   string `json:"reason"`
+	Repaired bool   `json:"repaired"`
+}
+
+// ConsistencyReport 一次巡检的结果
+type ConsistencyReport struct {
+	CheckedAt time.Time `json:"checked_at"`
+	Sampled   int       `json:"sampled"`
+	Drifts    []Drift   `json:"drifts"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// ConsistencyChecker 定期抽样缓存中的订单并与数据库比对
+type ConsistencyChecker struct {
+	repo   *repository.OrderRepository
+	store  cache.Store
+	config config.CacheConsistencyConfig
+
+	mu   sync.RWMutex
+	last *ConsistencyReport
+}
+
+func NewConsistencyChecker(repo *repository.OrderRepository, store cache.Store, cfg *config.RedisConfig) *ConsistencyChecker {
+	return &ConsistencyChecker{
+		repo:   repo,
+		store:  store,
+		config: cfg.Consistency,
+	}
+}
+
+// Run 按配置的间隔持续巡检，直到ctx被取消
+func (c *ConsistencyChecker) Run(ctx context.Context) {
+	if !c.config.Enabled {
+		return
+	}
+	interval := time.Duration(c.config.IntervalSeconds) * time.Second
+	if interval <= 0 {
+		interval = 5 * time.Minute
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			report := c.Check(ctx)
+			if report.Error != "" {
+				log.Printf("Cache consistency check failed: %s", report.Error)
+			} else if len(report.Drifts) > 0 {
+				log.Printf("Cache consistency check found %d drifted orders out of %d sampled", len(report.Drifts), report.Sampled)
+			}
+		}
+	}
+}
+
+// Check 执行一次巡检，按配置决定是否修复
+func (c *ConsistencyChecker) Check(ctx context.Context) *ConsistencyReport {
+	report := &ConsistencyReport{CheckedAt: time.Now(), Drifts: []Drift{}}
+	if err := c.check(ctx, report); err != nil {
+		report.Error = err.Error()
+	}
+
+	c.mu.Lock()
+	c.last = report
+	c.mu.Unlock()
+	return report
+}
+
+// LastReport 返回最近一次巡检结果
+func (c *ConsistencyChecker) LastReport() *ConsistencyReport {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.last
+}
+
+func (c *ConsistencyChecker) check(ctx context.Context, report *ConsistencyReport) error {
+	sampleSize := c.config.SampleSize
+	if sampleSize <= 0 {
+		sampleSize = 100
+	}
+
+	cached, err := c.store.SampleOrders(ctx, sampleSize)
+	if err != nil {
+		return errors.Wrap(err, "failed to sample cache")
+	}
+	report.Sampled = len(cached)
+	if len(cached) == 0 {
+		return nil
+	}
+
+	ids := make([]string, len(cached))
+	for i, order := range cached {
+		ids[i] = order.ID
+	}
+	stored, err := c.repo.FindByIDs(ctx, ids)
+	if err != nil {
+		return err
+	}
+	byID := make(map[string]*model.Order, len(stored))
+	for i := range stored {
+		byID[stored[i].ID] = &stored[i]
+	}
+
+	for _, order := range cached {
+		reason := compareOrders(order, byID[order.ID])
+		if reason == "" {
+			continue
+		}
+
+		drift := Drift{OrderID: order.ID, Reason: reason}
+		if c.config.Repair {
+			if err := c.store.EvictOrder(ctx, order.ID); err != nil {
+				log.Printf("Failed to repair cached order %s: %v", order.ID, err)
+			} else {
+				drift.Repaired = true
+			}
+		}
+		report.Drifts = append(report.Drifts, drift)
+	}
+	return nil
+}
+
+// compareOrders 比较缓存与数据库中的订单，返回不一致的原因，一致时返回空字符串
+func compareOrders(cached, stored *model.Order) string {
+	switch {
+	case stored == nil:
+		return "order not found in database"
+	case cached.UserID == "":
+		return "cached entry cannot be decoded"
+	case cached.Status != stored.Status:
+		return "status mismatch"
+	case cached.Amount != stored.Amount:
+		return "amount mismatch"
+	case len(cached.Items) != len(stored.Items):
+		return "items mismatch"
+	case !cached.UpdatedAt.Equal(stored.UpdatedAt):
+		return "updated_at mismatch"
+	}
+	return ""
+}
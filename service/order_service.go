@@ -2,17 +2,43 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 	"order_api/errors"
 	"order_api/model"
-	"order_api/repository"
+	"time"
 )
 
+// OrderStore 订单服务依赖的仓储操作，由 repository.OrderRepository 实现
+type OrderStore interface {
+	ListByUserID(ctx context.Context, userID string) ([]model.Order, error)
+	Create(ctx context.Context, order *model.Order) error
+	GetByID(ctx context.Context, orderID string) (*model.Order, error)
+	GetByNumber(ctx context.Context, number string) (*model.Order, error)
+	GetForUpdate(ctx context.Context, orderID string) (*model.Order, error)
+	Update(ctx context.Context, order *model.Order) error
+	Delete(ctx context.Context, orderID string) error
+	Restore(ctx context.Context, orderID string) (*model.Order, error)
+}
+
+// Transactor 在同一事务中执行多个仓储操作，事务通过 ctx 传递给仓储方法
+type Transactor interface {
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// EventStore 写入领域事件，由 repository.OutboxRepository 实现
+type EventStore interface {
+	Append(ctx context.Context, events ...*model.OutboxEvent) error
+}
+
 type OrderService struct {
-	repo *repository.OrderRepository
+	repo   OrderStore
+	tx     Transactor
+	events EventStore
 }
 
-func NewOrderService(repo *repository.OrderRepository) *OrderService {
-	return &OrderService{repo: repo}
+// NewOrderService 创建订单服务，events 为 nil 时不记录领域事件
+func NewOrderService(repo OrderStore, tx Transactor, events EventStore) *OrderService {
+	return &OrderService{repo: repo, tx: tx, events: events}
 }
 
 // ListOrders 获取用户的订单列表
@@ -29,16 +55,21 @@ func (s *OrderService) CreateOrder(ctx context.Context, order *model.Order) erro
 	order.CalculateAmount()
 	order.Status = model.StatusPending
 
-	if err := s.repo.Create(ctx, order); err != nil {
-		return errors.Wrap(err, "failed to create order")
-	}
-
-	return nil
+	// 订单与创建事件在同一事务中写入
+	return s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.Create(ctx, order); err != nil {
+			return errors.Wrap(err, "failed to create order")
+		}
+		return s.recordEvent(ctx, model.EventOrderCreated, order, "")
+	})
 }
 
-// GetOrder 获取订单详情
+// GetOrder 获取订单详情，orderID 也可以是订单号
 func (s *OrderService) GetOrder(ctx context.Context, orderID, userID string) (*model.Order, error) {
 	order, err := s.repo.GetByID(ctx, orderID)
+	if errors.Is(err, errors.ErrOrderNotFound) {
+		order, err = s.repo.GetByNumber(ctx, orderID)
+	}
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to get order")
 	}
@@ -52,38 +83,97 @@ func (s *OrderService) GetOrder(ctx context.Context, orderID, userID string) (*m
 }
 
 // UpdateOrderStatus 更新订单状态
+// 在事务中锁定订单后校验状态流转，避免并发请求基于过期状态写入
 func (s *OrderService) UpdateOrderStatus(ctx context.Context, orderID, userID, newStatus string) error {
-	order, err := s.GetOrder(ctx, orderID, userID)
+	return s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		order, err := s.lockOrder(ctx, orderID, userID)
+		if err != nil {
+			return err
+		}
+
+		if !model.IsValidStatusTransition(order.Status, newStatus) {
+			return errors.Wrap(errors.ErrInvalidOrderStatus, "invalid status transition")
+		}
+
+		previousStatus := order.Status
+		order.Status = newStatus
+		if err := s.repo.Update(ctx, order); err != nil {
+			return errors.Wrap(err, "failed to update order")
+		}
+
+		return s.recordEvent(ctx, model.EventOrderStatusChanged, order, previousStatus)
+	})
+}
+
+// DeleteOrder 删除订单
+func (s *OrderService) DeleteOrder(ctx context.Context, orderID, userID string) error {
+	return s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		order, err := s.lockOrder(ctx, orderID, userID)
+		if err != nil {
+			return err
+		}
+
+		if !model.CanDelete(order.Status) {
+			return errors.Wrap(errors.ErrInvalidOrderStatus, "order cannot be deleted")
+		}
+
+		if err := s.repo.Delete(ctx, orderID); err != nil {
+			return errors.Wrap(err, "failed to delete order")
+		}
+
+		return nil
+	})
+}
+
+// RestoreOrder 恢复已删除的订单及其订单项，仅供管理员使用
+func (s *OrderService) RestoreOrder(ctx context.Context, orderID string) (*model.Order, error) {
+	order, err := s.repo.Restore(ctx, orderID)
 	if err != nil {
-		return err
+		return nil, errors.Wrap(err, "failed to restore order")
 	}
+	return order, nil
+}
 
-	if !model.IsValidStatusTransition(order.Status, newStatus) {
-		return errors.Wrap(errors.ErrInvalidOrderStatus, "invalid status transition")
+// lockOrder 在事务中锁定订单并校验所属权
+func (s *OrderService) lockOrder(ctx context.Context, orderID, userID string) (*model.Order, error) {
+	order, err := s.repo.GetForUpdate(ctx, orderID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get order")
 	}
 
-	order.Status = newStatus
-	if err := s.repo.Update(ctx, order); err != nil {
-		return errors.Wrap(err, "failed to update order")
+	if order.UserID != userID {
+		return nil, errors.ErrUnauthorized
 	}
 
-	return nil
+	return order, nil
 }
 
-// DeleteOrder 删除订单
-func (s *OrderService) DeleteOrder(ctx context.Context, orderID, userID string) error {
-	order, err := s.GetOrder(ctx, orderID, userID)
-	if err != nil {
-		return err
+// recordEvent 在当前事务中写入订单事件
+func (s *OrderService) recordEvent(ctx context.Context, eventType string, order *model.Order, previousStatus string) error {
+	if s.events == nil {
+		return nil
 	}
 
-	if !model.CanDelete(order.Status) {
-		return errors.Wrap(errors.ErrInvalidOrderStatus, "order cannot be deleted")
+	payload, err := json.Marshal(model.OrderEventPayload{
+		OrderID:        order.ID,
+		OrderNumber:    order.Number,
+		UserID:         order.UserID,
+		Status:         order.Status,
+		PreviousStatus: previousStatus,
+		Amount:         order.Amount,
+		OccurredAt:     time.Now(),
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to encode order event")
 	}
 
-	if err := s.repo.Delete(ctx, orderID); err != nil {
-		return errors.Wrap(err, "failed to delete order")
+	err = s.events.Append(ctx, &model.OutboxEvent{
+		AggregateID: order.ID,
+		EventType:   eventType,
+		Payload:     string(payload),
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to record order event")
 	}
-
 	return nil
-}
\ No newline at end of file
+}
@@ -0,0 +1,180 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"order_api/config"
+	"order_api/model"
+	"order_api/outbox"
+	"order_api/repository"
+	"time"
+)
+
+// maxOutboxBackoff 投递重试的最大等待时间
+const maxOutboxBackoff = 10 * time.Minute
+
+// OutboxRelay 轮询发件箱并将事件投递给发布器
+type OutboxRelay struct {
+	repo      *repository.OutboxRepository
+	publisher outbox.Publisher
+	config    config.OutboxConfig
+}
+
+func NewOutboxRelay(repo *repository.OutboxRepository, publisher outbox.Publisher, cfg *config.OutboxConfig) *OutboxRelay {
+	return &OutboxRelay{
+		repo:      repo,
+		publisher: publisher,
+		config:    *cfg,
+	}
+}
+
+// Run 按配置的间隔持续投递，直到ctx被取消
+func (r *OutboxRelay) Run(ctx context.Context) {
+	if !r.config.Enabled {
+		return
+	}
+	interval := time.Duration(r.config.PollIntervalSeconds) * time.Second
+	if interval <= 0 {
+		interval = time.Second
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	lastPurge := time.Now()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.drain(ctx)
+			if time.Since(lastPurge) >= time.Hour {
+				r.purge(ctx)
+				lastPurge = time.Now()
+			}
+		}
+	}
+}
+
+// drain 连续投递直到没有可领取的事件
+func (r *OutboxRelay) drain(ctx context.Context) {
+	for ctx.Err() == nil {
+		n, err := r.RelayOnce(ctx)
+		if err != nil {
+			log.Printf("Outbox relay failed: %v", err)
+			return
+		}
+		if n == 0 {
+			return
+		}
+	}
+}
+
+// RelayOnce 在每个分片上领取并投递一批事件，返回本轮领取的事件数量
+func (r *OutboxRelay) RelayOnce(ctx context.Context) (int, error) {
+	total := 0
+	for shard := 0; shard < r.repo.Shards(); shard++ {
+		events, err := r.repo.Claim(ctx, shard, r.batchSize(), r.lease())
+		if err != nil {
+			return total, err
+		}
+		for i := range events {
+			r.deliver(ctx, &events[i])
+		}
+		total += len(events)
+	}
+	return total, nil
+}
+
+func (r *OutboxRelay) deliver(ctx context.Context, event *model.OutboxEvent) {
+	msg := &outbox.Message{
+		ID:          event.ID,
+		Shard:       event.Shard,
+		Type:        event.EventType,
+		AggregateID: event.AggregateID,
+		Payload:     json.RawMessage(event.Payload),
+		CreatedAt:   event.CreatedAt,
+	}
+
+	publishErr := r.publisher.Publish(ctx, msg)
+	if publishErr == nil {
+		if err := r.repo.MarkDelivered(ctx, event.Shard, event.ID); err != nil {
+			// 租约到期后事件会被再次投递，由下游按事件ID去重
+			log.Printf("Failed to mark outbox event %d delivered: %v", event.ID, err)
+		}
+		return
+	}
+
+	attempts := event.Attempts + 1
+	dead := attempts >= r.maxAttempts()
+	if dead {
+		log.Printf("Outbox event %d %s moved to dead letter after %d attempts: %v", event.ID, event.EventType, attempts, publishErr)
+	} else {
+		log.Printf("Failed to publish outbox event %d %s (attempt %d/%d): %v", event.ID, event.EventType, attempts, r.maxAttempts(), publishErr)
+	}
+	retryAt := time.Now().Add(r.backoff(attempts))
+	if err := r.repo.MarkFailed(ctx, event.Shard, event.ID, publishErr.Error(), retryAt, dead); err != nil {
+		log.Printf("Failed to record outbox event %d failure: %v", event.ID, err)
+	}
+}
+
+// purge 清理超过保留时间的已投递事件
+func (r *OutboxRelay) purge(ctx context.Context) {
+	if r.config.RetentionHours <= 0 {
+		return
+	}
+	before := time.Now().Add(-time.Duration(r.config.RetentionHours) * time.Hour)
+	if _, err := r.repo.PurgeDelivered(ctx, before); err != nil {
+		log.Printf("Failed to purge delivered outbox events: %v", err)
+	}
+}
+
+// backoff 第 attempts 次失败后的等待时间，按指数递增
+func (r *OutboxRelay) backoff(attempts int) time.Duration {
+	backoff := time.Duration(r.config.RetryBackoff) * time.Second
+	if backoff <= 0 {
+		backoff = time.Second
+	}
+	for i := 1; i < attempts && backoff < maxOutboxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > maxOutboxBackoff {
+		backoff = maxOutboxBackoff
+	}
+	return backoff
+}
+
+func (r *OutboxRelay) batchSize() int {
+	if r.config.BatchSize <= 0 {
+		return 100
+	}
+	return r.config.BatchSize
+}
+
+func (r *OutboxRelay) maxAttempts() int {
+	if r.config.MaxAttempts <= 0 {
+		return 10
+	}
+	return r.config.MaxAttempts
+}
+
+func (r *OutboxRelay) lease() time.Duration {
+	if r.config.LeaseSeconds <= 0 {
+		return time.Minute
+	}
+	return time.Duration(r.config.LeaseSeconds) * time.Second
+}
+
+// DeadLetters 获取死信事件
+func (r *OutboxRelay) DeadLetters(ctx context.Context, limit int) ([]model.OutboxEvent, error) {
+	if limit <= 0 || limit > 1000 {
+		limit = 100
+	}
+	return r.repo.ListDead(ctx, limit)
+}
+
+// Retry 将指定分片的死信事件重新放回投递队列
+func (r *OutboxRelay) Retry(ctx context.Context, shard int, id int64) error {
+	return r.repo.Requeue(ctx, shard, id)
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"order_api/model"
+	"order_api/repository"
+	"sort"
+	"time"
+)
+
+// OrderReport 跨分片的订单统计报表
+type OrderReport struct {
+	From     time.Time               `json:"from"`
+	To       time.Time               `json:"to"`
+	Count    int64                   `json:"count"`
+	Amount   float64                 `json:"amount"`
+	Statuses []model.OrderStatusStat `json:"statuses"`
+	Shards   []ShardOrderReport      `json:"shards"`
+}
+
+// ShardOrderReport 单个分片的订单统计
+type ShardOrderReport struct {
+	Shard    int                     `json:"shard"`
+	Count    int64                   `json:"count"`
+	Amount   float64                 `json:"amount"`
+	Statuses []model.OrderStatusStat `json:"statuses"`
+}
+
+// ReportService 管理端报表，查询会扇出到全部分片
+type ReportService struct {
+	repo *repository.OrderRepository
+}
+
+func NewReportService(repo *repository.OrderRepository) *ReportService {
+	return &ReportService{
+		repo: repo,
+	}
+}
+
+// OrderReport 统计 [from, to) 内创建的订单，给出各分片明细和汇总
+func (s *ReportService) OrderReport(ctx context.Context, from, to time.Time) (*OrderReport, error) {
+	shards, err := s.repo.StatusStatsByShard(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &OrderReport{
+		From:     from,
+		To:       to,
+		Statuses: []model.OrderStatusStat{},
+		Shards:   make([]ShardOrderReport, 0, len(shards)),
+	}
+	totals := make(map[string]*model.OrderStatusStat)
+	for shard, stats := range shards {
+		shardReport := ShardOrderReport{Shard: shard, Statuses: []model.OrderStatusStat{}}
+		for _, stat := range stats {
+			shardReport.Count += stat.Count
+			shardReport.Amount += stat.Amount
+			shardReport.Statuses = append(shardReport.Statuses, stat)
+
+			total, ok := totals[stat.Status]
+			if !ok {
+				total = &model.OrderStatusStat{Status: stat.Status}
+				totals[stat.Status] = total
+			}
+			total.Count += stat.Count
+			total.Amount += stat.Amount
+		}
+		report.Count += shardReport.Count
+		report.Amount += shardReport.Amount
+		report.Shards = append(report.Shards, shardReport)
+	}
+
+	for _, total := range totals {
+		report.Statuses = append(report.Statuses, *total)
+	}
+	sort.Slice(report.Statuses, func(i, j int) bool {
+		return report.Statuses[i].Status < report.Statuses[j].Status
+	})
+	return report, nil
+}
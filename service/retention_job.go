@@ -0,0 +1,203 @@
+package service
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"order_api/config"
+	"order_api/errors"
+	"order_api/model"
+	"order_api/repository"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// RetentionReport 一次保留任务的执行结果
+type RetentionReport struct {
+	StartedAt    time.Time `json:"started_at"`
+	Purged       int       `json:"purged"`        // 物理删除的软删除订单数
+	Archived     int       `json:"archived"`      // 归档的订单数
+	ArchiveFiles []string  `json:"archive_files"` // 本次生成的归档文件
+	Error        string    `json:"error,omitempty"`
+}
+
+// RetentionJob 定期清理到期的软删除订单，并归档长期已完成的订单
+type RetentionJob struct {
+	repo   *repository.OrderRepository
+	config config.RetentionConfig
+
+	// mu 保证同一进程内任务不会并发执行
+	mu sync.Mutex
+}
+
+func NewRetentionJob(repo *repository.OrderRepository, cfg *config.RetentionConfig) *RetentionJob {
+	return &RetentionJob{
+		repo:   repo,
+		config: *cfg,
+	}
+}
+
+// Run 按配置的间隔持续执行，直到ctx被取消
+func (j *RetentionJob) Run(ctx context.Context) {
+	if !j.config.Enabled {
+		return
+	}
+	interval := time.Duration(j.config.IntervalMinutes) * time.Minute
+	if interval <= 0 {
+		interval = time.Hour
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			report := j.RunOnce(ctx)
+			if report.Error != "" {
+				log.Printf("Order retention job failed: %s", report.Error)
+			} else if report.Purged > 0 || report.Archived > 0 {
+				log.Printf("Order retention job purged %d and archived %d orders", report.Purged, report.Archived)
+			}
+		}
+	}
+}
+
+// RunOnce 立即执行一次保留任务
+func (j *RetentionJob) RunOnce(ctx context.Context) *RetentionReport {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	report := &RetentionReport{StartedAt: time.Now(), ArchiveFiles: []string{}}
+	if err := j.run(ctx, report); err != nil {
+		report.Error = err.Error()
+	}
+	return report
+}
+
+func (j *RetentionJob) run(ctx context.Context, report *RetentionReport) error {
+	if days := j.config.DeletedRetentionDays; days > 0 {
+		before := report.StartedAt.AddDate(0, 0, -days)
+		err := j.drain(ctx, func(limit int) ([]model.Order, error) {
+			return j.repo.ListDeletedBefore(ctx, before, limit)
+		}, func(orders []model.Order) error {
+			if j.config.DeletedAction == config.RetentionActionArchive {
+				if err := j.archive(ctx, orders, report); err != nil {
+					return err
+				}
+				report.Archived += len(orders)
+				return nil
+			}
+			if err := j.repo.Purge(ctx, orders); err != nil {
+				return err
+			}
+			report.Purged += len(orders)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if years := j.config.ArchiveAfterYears; years > 0 {
+		before := report.StartedAt.AddDate(-years, 0, 0)
+		err := j.drain(ctx, func(limit int) ([]model.Order, error) {
+			return j.repo.ListCompletedBefore(ctx, before, limit)
+		}, func(orders []model.Order) error {
+			if err := j.archive(ctx, orders, report); err != nil {
+				return err
+			}
+			report.Archived += len(orders)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// drain 分批读取并处理订单，直到没有符合条件的订单
+func (j *RetentionJob) drain(ctx context.Context, list func(limit int) ([]model.Order, error), handle func([]model.Order) error) error {
+	batchSize := j.config.BatchSize
+	if batchSize <= 0 {
+		batchSize = 500
+	}
+
+	for ctx.Err() == nil {
+		orders, err := list(batchSize)
+		if err != nil {
+			return err
+		}
+		if len(orders) == 0 {
+			return nil
+		}
+		if err := handle(orders); err != nil {
+			return err
+		}
+		if len(orders) < batchSize {
+			return nil
+		}
+	}
+	return ctx.Err()
+}
+
+// archive 按配置的目标归档订单并删除原数据
+func (j *RetentionJob) archive(ctx context.Context, orders []model.Order, report *RetentionReport) error {
+	if j.config.ArchiveTarget != config.ArchiveTargetJSONL {
+		return j.repo.ArchiveToTable(ctx, orders)
+	}
+
+	// 归档文件完整写入并落盘后才删除数据库中的订单
+	path, err := j.writeJSONL(orders)
+	if err != nil {
+		return err
+	}
+	report.ArchiveFiles = append(report.ArchiveFiles, path)
+	return j.repo.Purge(ctx, orders)
+}
+
+// writeJSONL 将一批订单写入 gzip 压缩的 JSONL 文件，每行一个订单
+func (j *RetentionJob) writeJSONL(orders []model.Order) (string, error) {
+	if err := os.MkdirAll(j.config.ArchiveDir, 0o755); err != nil {
+		return "", errors.Wrap(err, "failed to create archive directory")
+	}
+
+	name := fmt.Sprintf("orders-%s-%s.jsonl.gz", time.Now().Format("20060102-150405.000"), orders[0].ID)
+	path := filepath.Join(j.config.ArchiveDir, name)
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to create archive file")
+	}
+
+	if err := writeOrders(file, orders); err != nil {
+		file.Close()
+		os.Remove(path)
+		return "", errors.Wrap(err, "failed to write archive file")
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(path)
+		return "", errors.Wrap(err, "failed to close archive file")
+	}
+	return path, nil
+}
+
+func writeOrders(file *os.File, orders []model.Order) error {
+	gz := gzip.NewWriter(file)
+	encoder := json.NewEncoder(gz)
+	for i := range orders {
+		if err := encoder.Encode(&orders[i]); err != nil {
+			return err
+		}
+	}
+	if err := gz.Close(); err != nil {
+		return err
+	}
+	return file.Sync()
+}
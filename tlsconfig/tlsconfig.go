@@ -0,0 +1,144 @@
+// Package tlsconfig 根据配置构建服务端 TLS 配置，证书文件变化时自动重新加载
+package tlsconfig
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"log"
+	"order_api/config"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// reloadDebounce 证书文件变化后等待的时间，证书和私钥通常先后写入
+const reloadDebounce = time.Second
+
+// Manager 持有当前生效的证书和客户端CA，新建连接时使用最新加载的版本，已建立的连接不受影响
+type Manager struct {
+	cfg     config.TLSConfig
+	current atomic.Pointer[tls.Config]
+}
+
+// New 加载证书并创建 Manager，证书或CA文件无法加载时返回错误
+func New(cfg *config.TLSConfig) (*Manager, error) {
+	m := &Manager{cfg: *cfg}
+	if err := m.Reload(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// TLSConfig 返回供 http.Server 使用的配置，每次握手时取最新加载的证书和CA
+func (m *Manager) TLSConfig() *tls.Config {
+	if m.cfg.DisableHTTP2 {
+		return m.ServerConfig("http/1.1")
+	}
+	return m.ServerConfig("h2", "http/1.1")
+}
+
+// ServerConfig 返回通过 ALPN 协商指定协议的配置，gRPC 服务只使用 h2
+func (m *Manager) ServerConfig(nextProtos ...string) *tls.Config {
+	return &tls.Config{
+		MinVersion: m.cfg.GetMinVersion(),
+		NextProtos: nextProtos,
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			return &m.current.Load().Certificates[0], nil
+		},
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			cfg := m.current.Load().Clone()
+			cfg.NextProtos = nextProtos
+			return cfg, nil
+		},
+	}
+}
+
+// Reload 重新读取证书、私钥和客户端CA，加载失败时保留当前证书
+func (m *Manager) Reload() error {
+	cert, err := tls.LoadX509KeyPair(m.cfg.CertFile, m.cfg.KeyFile)
+	if err != nil {
+		return fmt.Errorf("加载TLS证书失败: %w", err)
+	}
+	cipherSuites, err := m.cfg.GetCipherSuites()
+	if err != nil {
+		return err
+	}
+
+	next := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   m.cfg.GetMinVersion(),
+		CipherSuites: cipherSuites,
+		ClientAuth:   m.cfg.GetClientAuth(),
+	}
+	if m.cfg.ClientCAFile != "" {
+		pem, err := os.ReadFile(m.cfg.ClientCAFile)
+		if err != nil {
+			return fmt.Errorf("加载客户端CA失败: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("客户端CA文件%s中没有有效的证书", m.cfg.ClientCAFile)
+		}
+		next.ClientCAs = pool
+	}
+
+	m.current.Store(next)
+	if leaf, err := x509.ParseCertificate(cert.Certificate[0]); err == nil {
+		log.Printf("Loaded TLS certificate %s (subject %s, expires %s)", m.cfg.CertFile, leaf.Subject, leaf.NotAfter.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// Run 监听证书、私钥和CA文件直到 ctx 结束，文件变化时重新加载
+func (m *Manager) Run(ctx context.Context) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		log.Printf("TLS certificate watcher disabled: %v", err)
+		return
+	}
+	defer watcher.Close()
+
+	// 监听目录而不是文件：cert-manager 和 Kubernetes Secret 通过替换文件或符号链接更新证书
+	names := map[string]bool{"..data": true}
+	for _, file := range []string{m.cfg.CertFile, m.cfg.KeyFile, m.cfg.ClientCAFile} {
+		if file == "" {
+			continue
+		}
+		names[filepath.Base(file)] = true
+		if err := watcher.Add(filepath.Dir(file)); err != nil {
+			log.Printf("Failed to watch certificate directory %s: %v", filepath.Dir(file), err)
+		}
+	}
+
+	debounce := time.NewTimer(reloadDebounce)
+	debounce.Stop()
+	defer debounce.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			if names[filepath.Base(event.Name)] {
+				debounce.Reset(reloadDebounce)
+			}
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			log.Printf("TLS certificate watcher error: %v", err)
+		case <-debounce.C:
+			if err := m.Reload(); err != nil {
+				log.Printf("TLS certificate reload failed, keeping the current certificate: %v", err)
+			}
+		}
+	}
+}